@@ -9,16 +9,13 @@ import (
 	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
 	"log"
 	"log_analyzer/backend"
-	"log_analyzer/backend/analyzer"
 	"log_analyzer/backend/analyzer/entities"
 	"log_analyzer/backend/analyzer/installedIDEs"
 	"log_analyzer/backend/update"
 	"os"
-	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
-	"time"
 )
 
 // App struct
@@ -33,9 +30,14 @@ func NewApp() *App {
 
 // startup is called at application startup
 func (b *App) startup(ctx context.Context) {
-	loc, _ := time.LoadLocation("UTC")
-	time.Local = loc // -> this is setting the global timezone
 	b.ctx = ctx
+	backend.LoadCustomEntities()
+	backend.LoadPluginCatalog()
+	backend.LoadPluginPackages()
+	backend.LoadAlertRules()
+	backend.ApplyIdeSearchRoots()
+	backend.ApplyRunningIDEPorts()
+	backend.ApplyParsingSettings()
 	b.RenderSystemMenu()
 	b.CheckForUpdates()
 }
@@ -50,13 +52,14 @@ func (b *App) shutdown(ctx context.Context) {
 	entities.CurrentAnalyzer.Clear()
 }
 
-func (b *App) OpenIndexingSummaryForProject(fileName string) {
-	absolutePath := backend.GetIndexingFilePath(fileName)
-	wailsruntime.BrowserOpenURL(b.ctx, filepath.Dir(absolutePath)+string(filepath.Separator)+"report.html")
+// GetIndexingSummaryURL returns in-app URL of report.html of the project the indexing report belongs to
+func (b *App) GetIndexingSummaryURL(fileName string) string {
+	return backend.GetIndexingSummaryURL(fileName)
 }
-func (b *App) OpenIndexingReport(fileName string) {
-	absolutePath := backend.GetIndexingFilePath(fileName)
-	wailsruntime.BrowserOpenURL(b.ctx, absolutePath)
+
+// GetIndexingReportURL returns in-app URL of the indexing report
+func (b *App) GetIndexingReportURL(fileName string) string {
+	return backend.GetIndexingReportURL(fileName)
 }
 func (b *App) OpenFolder() string {
 	path, _ := wailsruntime.OpenDirectoryDialog(b.ctx, wailsruntime.OpenDialogOptions{
@@ -98,6 +101,100 @@ func (b *App) InitLogDirectory(path string) string {
 		return ""
 	}
 }
+
+// OpenLogFolder analyzes the folder chosen by user and adds it to the recent list. Returns empty string on failure
+func (b *App) OpenLogFolder(path string) string {
+	if path == "" {
+		return ""
+	}
+	if err := backend.OpenFolder(path, &b.ctx); err != nil {
+		log.Printf("Could not open folder %s: %s", path, err)
+		return ""
+	}
+	return path
+}
+
+// GetRecentItemsHTML returns the list of recently analyzed folders, archives and installed IDEs
+func (b *App) GetRecentItemsHTML() string {
+	return backend.GetRecentItems().ConvertToHTML()
+}
+
+// OpenRecentItem analyzes the item of the recent list again. Returns error message or empty string
+func (b *App) OpenRecentItem(index int) string {
+	if err := backend.OpenRecentItem(index, &b.ctx); err != nil {
+		log.Printf("Could not open recent item: %s", err)
+		return err.Error()
+	}
+	return ""
+}
+
+// RemoveRecentItem removes the item from the recent list. Returns error message or empty string
+func (b *App) RemoveRecentItem(index int) string {
+	if err := backend.RemoveRecentItem(index); err != nil {
+		log.Printf("Could not remove recent item: %s", err)
+		return err.Error()
+	}
+	return ""
+}
+
+// GetWorkspaceState returns JSON with search, scroll position and notes saved for analyzed logs, or empty string if they are not in the recent list
+func (b *App) GetWorkspaceState() string {
+	state, found := backend.GetWorkspaceState()
+	if !found {
+		return ""
+	}
+	marshal, _ := json.Marshal(state)
+	return string(marshal)
+}
+
+// SaveWorkspaceState saves search, scroll position, notes and filters of analyzed logs to the recent list
+func (b *App) SaveWorkspaceState(search string, scrollLine int, notes string) {
+	if err := backend.SaveWorkspaceState(search, scrollLine, notes); err != nil {
+		log.Printf("Could not save workspace state: %s", err)
+	}
+}
+
+// OpenInstalledIDE analyzes logs of the IDE selected in the list of installed IDEs and enables live update. Returns empty string on failure
+func (b *App) OpenInstalledIDE(logsDirectory string, includeSystemDiagnostics bool) string {
+	if err := backend.OpenInstalledIDE(logsDirectory, includeSystemDiagnostics, &b.ctx); err != nil {
+		log.Printf("Could not open logs of installed IDE: %s", err)
+		return ""
+	}
+	return logsDirectory
+}
+
+// GetIdeInspectorHTML returns read-only view of directories, plugins and vmoptions of the installed IDE. Empty string on failure
+func (b *App) GetIdeInspectorHTML(logsDirectory string) string {
+	html, err := backend.GetIdeInspectorHTML(logsDirectory)
+	if err != nil {
+		log.Printf("Could not inspect installed IDE: %s", err)
+		return ""
+	}
+	return html
+}
+
+// CollectSupportBundle saves logs, diagnostics and settings of the installed IDE to the zip chosen by user and returns the folder the bundle is unzipped to
+func (b *App) CollectSupportBundle(logsDirectory string) string {
+	path, _ := wailsruntime.SaveFileDialog(b.ctx, wailsruntime.SaveDialogOptions{
+		DefaultFilename: backend.GetSupportBundleFileName(logsDirectory),
+		Title:           "Collect logs and diagnostic data",
+		Filters: []wailsruntime.FileFilter{
+			{
+				DisplayName: "ZIP",
+				Pattern:     "*.zip",
+			},
+		},
+	})
+	if path == "" {
+		return ""
+	}
+	if err := backend.CollectSupportBundle(logsDirectory, path); err != nil {
+		log.Printf("Could not collect support bundle to %s: %s", path, err)
+		return ""
+	}
+	log.Println("Collected support bundle: " + path)
+	return b.openArchive(path)
+}
 func (b *App) UploadArchive(DataURIScheme string) string {
 	data := ConvertDataURISchemeToBase64File(DataURIScheme)
 	f, err := os.CreateTemp("", "IntelliJLogsAnalyzer-temp.zip")
@@ -121,7 +218,10 @@ func (b *App) UploadArchive(DataURIScheme string) string {
 	} else {
 		log.Printf("Removed temp archive: %s", f.Name())
 	}
-	return b.InitLogDirectory(unzippedDir)
+	if err := backend.InitTempLogDirectory(unzippedDir, &b.ctx); err != nil {
+		return ""
+	}
+	return unzippedDir
 }
 func (b *App) OpenArchive() string {
 	path, _ := wailsruntime.OpenFileDialog(b.ctx, wailsruntime.OpenDialogOptions{
@@ -143,20 +243,22 @@ func (b *App) OpenArchive() string {
 	if path == "" {
 		return ""
 	}
-	unzippedDir := backend.UnzipToTempFodler(path)
-	log.Println("Unzipped files to path: " + path)
-	return unzippedDir
+	return b.openArchive(path)
 }
 
-func (b *App) GetLogs() string {
-	logs := *backend.GetLogs()
-	logsToDisplay := analyzer.Logs{}
-	for i, entry := range logs {
-		if entry.Visible == true {
-			logsToDisplay = append(logsToDisplay, logs[i])
-		}
+// openArchive analyzes the content of the archive and adds it to the recent list. Returns the folder with the content or empty string on failure
+func (b *App) openArchive(path string) string {
+	dir, err := backend.OpenArchive(path, &b.ctx)
+	if err != nil {
+		log.Printf("Could not open archive %s: %s", path, err)
+		return ""
 	}
-	html := logsToDisplay.ConvertToHTML()
+	log.Println("Analyzing content of " + path + " in " + dir)
+	return dir
+}
+
+func (b *App) GetLogs() string {
+	html := backend.GetVisibleLogs().ConvertToHTML()
 	return html
 }
 func (b *App) GetStaticInfo() string {
@@ -165,6 +267,55 @@ func (b *App) GetStaticInfo() string {
 	return html
 }
 
+// ExportStaticInfo saves collected environment information as JSON file chosen by user
+func (b *App) ExportStaticInfo() {
+	path, _ := wailsruntime.SaveFileDialog(b.ctx, wailsruntime.SaveDialogOptions{
+		DefaultFilename: "environment.json",
+		Title:           "Export environment information",
+		Filters: []wailsruntime.FileFilter{
+			{
+				DisplayName: "JSON",
+				Pattern:     "*.json",
+			},
+		},
+	})
+	if path == "" {
+		return
+	}
+	err := os.WriteFile(path, []byte(backend.GetStaticInfo().ConvertToJSON()), 0644)
+	if err != nil {
+		log.Printf("Could not export static info to %s: %s", path, err)
+	}
+}
+
+// GetGCChart returns HTML with heap occupancy and GC pauses charts. Empty string if there are no GC logs
+func (b *App) GetGCChart() string {
+	return backend.GetGCEvents().ConvertToHTML()
+}
+
+// GetIndexingHistory returns HTML with indexing durations chart and stats per project. Empty string if there is no indexing diagnostic
+func (b *App) GetIndexingHistory() string {
+	return backend.GetIndexingHistory().ConvertToHTML()
+}
+
+// GetCrashReports returns HTML with summaries of JVM crashes. Empty string if there are no fatal error logs
+func (b *App) GetCrashReports() string {
+	return backend.GetCrashReports().ConvertToHTML()
+}
+func (b *App) GetCrashReportContent(id string) string {
+	return backend.GetCrashReports().GetContent(id)
+}
+
+// GetPluginErrors returns HTML with the number of errors caused by every plugin. Empty string if no errors are attributed to plugins
+func (b *App) GetPluginErrors() string {
+	return backend.GetPluginErrors().ConvertToHTML()
+}
+
+// GetAlerts returns HTML with alerts raised during live update. Empty string if there are no alerts
+func (b *App) GetAlerts() string {
+	return backend.GetAlerts().ConvertToHTML()
+}
+
 func (b *App) GetSummary() string {
 	return backend.GetFilters().ConvertToHTML() + backend.GetOtherFiles().ConvertToHTML()
 }
@@ -190,7 +341,7 @@ func (b *App) GetThreadDumpsFilters(dir string) string {
 func (b *App) SetFilters(a map[string]bool) string {
 	err := backend.SetFilters(a)
 	if err == nil {
-		backend.GetLogs().ApplyFilters(backend.GetFilters())
+		return "success"
 	}
 	return "failure"
 }
@@ -331,16 +482,46 @@ func (b *App) GetSettingsScreenHTML() string {
 func (b *App) GetRunningIDEsDropdownHTML() string {
 	return installedIDEs.GetInstalledIDEsDropdownHTML()
 }
-func (b *App) SaveSetting(key string, value interface{}) {
-	backend.GetConfig().SaveSetting(key, value)
+
+// SaveSetting validates and saves the setting. Returns error message or empty string.
+func (b *App) SaveSetting(key string, value interface{}) string {
+	if err := backend.SaveSetting(key, value); err != nil {
+		log.Printf("Could not save setting '%s': %s", key, err)
+		return err.Error()
+	}
+	if key == "DisplayTimeZone" {
+		backend.ApplyDisplayTimeZone()
+		wailsruntime.EventsEmit(b.ctx, "LogsTimeZoneChanged")
+	}
+	if key == "IdeSearchRoots" {
+		backend.ApplyIdeSearchRoots()
+	}
+	if key == "RunningIDEFirstPort" || key == "RunningIDELastPort" {
+		backend.ApplyRunningIDEPorts()
+	}
+	if backend.IsParsingSetting(key) {
+		backend.ApplyParsingSettings()
+	}
 	wailsruntime.EventsEmit(b.ctx, "SettingsChanged", backend.GetConfig())
-	log.Println(b.ctx)
+	return ""
 }
 func (b *App) GetSetting(key string) interface{} {
-	ptr := reflect.ValueOf(backend.GetConfig())
-	s := reflect.Indirect(ptr).FieldByName(key).Interface()
+	s := reflect.ValueOf(backend.GetConfig()).FieldByName(key).Interface()
 	return s
 }
+func (b *App) GetSourceTimeZone() string {
+	return backend.GetSourceTimeZone()
+}
+
+// SetSourceTimeZone overrides detected time zone of the analyzed logs. Returns error message or empty string.
+func (b *App) SetSourceTimeZone(name string) string {
+	if err := backend.SetSourceTimeZone(name); err != nil {
+		log.Printf("Could not set source time zone '%s': %s", name, err)
+		return err.Error()
+	}
+	wailsruntime.EventsEmit(b.ctx, "LogsTimeZoneChanged")
+	return ""
+}
 func (b *App) EnableLogsLiveUpdate() {
 	backend.EnableLogsLiveUpdate()
 }
@@ -0,0 +1,36 @@
+package backend
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"log_analyzer/backend/analyzer"
+	"log_analyzer/backend/analyzer/entities"
+	"os"
+)
+
+var AlertRulesFileName = "alert-rules.json"
+
+//LoadAlertRules loads rules checked during live update from the configuration directory, if the file exists.
+//The file is a JSON array of analyzer.AlertRule, for example [{"Name": "Errors", "MinSeverity": "ERROR"}, {"Name": "Freeze", "PathPattern": "^threadDumps-freeze-"}].
+func LoadAlertRules() {
+	path := getConfigDir() + string(os.PathSeparator) + ConfigDirectoryName + string(os.PathSeparator) + AlertRulesFileName
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Printf("Alert rules %s not found, live update will not raise alerts", path)
+		return
+	}
+	var rules []analyzer.AlertRule
+	if err = json.Unmarshal(content, &rules); err != nil {
+		log.Printf("Could not load alert rules %s: %s", path, err)
+		return
+	}
+	if err = analyzer.SetAlertRules(rules); err != nil {
+		log.Printf("Some alert rules of %s are skipped: %s", path, err)
+	}
+	log.Printf("Loaded alert rules from %s", path)
+}
+
+func GetAlerts() analyzer.Alerts {
+	return entities.CurrentAnalyzer.GetAlerts()
+}
@@ -1,30 +1,114 @@
 package backend
 
 import (
-	"archive/zip"
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"log"
 	"log_analyzer/backend/analyzer"
 	"log_analyzer/backend/analyzer/entities"
+	"log_analyzer/backend/analyzer/installedIDEs"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 )
 
 //InitLogDirectory creates an instance of analyzed directory (all entities combined) and parses them
 func InitLogDirectory(path string, ctx *context.Context) (err error) {
+	return initLogDirectories(ctx, path, false)
+}
+
+//InitTempLogDirectory analyzes the folder like InitLogDirectory. The folder is removed when other logs are opened.
+func InitTempLogDirectory(path string, ctx *context.Context) (err error) {
+	return initLogDirectories(ctx, path, true)
+}
+
+//OpenInstalledIDE analyzes logs of the IDE found on this machine (identified by its logs directory) and enables live update.
+//If includeSystemDiagnostics is set, indexing diagnostics and thread dumps stored in the system directory of the IDE are analyzed too.
+func OpenInstalledIDE(logsDirectory string, includeSystemDiagnostics bool, ctx *context.Context) error {
+	ide, err := findInstalledIDE(logsDirectory)
+	if err != nil {
+		return err
+	}
+	var additionalFolders []string
+	if includeSystemDiagnostics {
+		additionalFolders = ide.SystemDiagnosticFolders()
+	}
+	if err := initLogDirectories(ctx, ide.Info.LogsDirectory, false, additionalFolders...); err != nil {
+		return err
+	}
+	rememberRecentItem(RecentItem{
+		Kind:                     RecentIDE,
+		Path:                     ide.Info.LogsDirectory,
+		Name:                     strings.TrimSpace(ide.Info.Name + " " + ide.Info.Version),
+		IncludeSystemDiagnostics: includeSystemDiagnostics,
+	})
+	EnableLogsLiveUpdate()
+	return nil
+}
+
+//CollectSupportBundle writes logs, diagnostics and settings of the IDE found on this machine to zipPath with secrets redacted
+func CollectSupportBundle(logsDirectory string, zipPath string) error {
+	ide, err := findInstalledIDE(logsDirectory)
+	if err != nil {
+		return err
+	}
+	return installedIDEs.CollectSupportBundle(ide, zipPath)
+}
+
+//GetSupportBundleFileName returns the default name of the support bundle, for example IntelliJIdea2022.2-logs-20221018-1530.zip
+func GetSupportBundleFileName(logsDirectory string) string {
+	name := "IDE"
+	if ide, err := findInstalledIDE(logsDirectory); err == nil && len(ide.Info.DataDirectoryName) > 0 {
+		name = ide.Info.DataDirectoryName
+	}
+	return name + "-logs-" + time.Now().Format("20060102-1504") + ".zip"
+}
+
+//GetIdeInspectorHTML returns effective directories, installed plugins, system directory usage and vmoptions of the IDE found on this machine
+func GetIdeInspectorHTML(logsDirectory string) (string, error) {
+	ide, err := findInstalledIDE(logsDirectory)
+	if err != nil {
+		return "", err
+	}
+	return installedIDEs.Inspect(ide).ConvertToHTML(), nil
+}
+
+//findInstalledIDE returns the IDE with the logs directory, installations are scanned again if it is not found among the last scanned ones
+func findInstalledIDE(logsDirectory string) (installedIDEs.IDE, error) {
+	ide, found := installedIDEs.FindInstallation(logsDirectory)
+	if !found {
+		installedIDEs.GetIdeInstallations()
+		ide, found = installedIDEs.FindInstallation(logsDirectory)
+	}
+	if !found {
+		return ide, fmt.Errorf("IDE with logs directory %s is not found", logsDirectory)
+	}
+	return ide, nil
+}
+
+//initLogDirectories analyzes the folders instead of currently analyzed ones. isTemp marks the folder to be removed when other logs are opened.
+func initLogDirectories(ctx *context.Context, path string, isTemp bool, additionalFolders ...string) (err error) {
+	forgetCurrentRecentItem()
 	entities.CurrentAnalyzer.Clear()
+	entities.CurrentAnalyzer.IsFolderTemp = isTemp
 	entities.CurrentAnalyzer.Context = ctx
 	entities.CurrentAnalyzer.FolderToWorkWith = path
+	entities.CurrentAnalyzer.AdditionalFolders = additionalFolders
 	timeStart := time.Now()
 	entities.CurrentAnalyzer.ParseLogDirectory(path)
+	for _, folder := range additionalFolders {
+		entities.CurrentAnalyzer.ParseLogDirectory(folder)
+	}
 	log.Printf("Parsed Logs in %s \n Log Entries Count: %d", time.Now().Sub(timeStart).String(), len(entities.CurrentAnalyzer.AggregatedLogs))
-	entities.CurrentAnalyzer.AggregatedLogs.SortByTime()
+	sourceTimeZone, detectedFrom := entities.CurrentAnalyzer.DetectSourceTimeZone()
+	if len(detectedFrom) > 0 {
+		log.Printf("Detected time zone %s from %s", sourceTimeZone, detectedFrom)
+	}
+	entities.CurrentAnalyzer.SetSourceTimeZone(sourceTimeZone)
+	ApplyDisplayTimeZone()
+	entities.CurrentAnalyzer.GetPluginErrors()
 	entities.CurrentAnalyzer.GenerateFilters()
 	if entities.CurrentAnalyzer.IsEmpty() {
 		return errors.New("could not find logs elements inside")
@@ -33,9 +117,92 @@ func InitLogDirectory(path string, ctx *context.Context) (err error) {
 	}
 }
 
+//SetSourceTimeZone overrides the time zone the logs of currently analyzed bundle were written in
+func SetSourceTimeZone(name string) error {
+	loc, err := analyzer.LoadTimeZone(name)
+	if err != nil {
+		return err
+	}
+	entities.CurrentAnalyzer.SetSourceTimeZone(loc)
+	ApplyDisplayTimeZone()
+	return nil
+}
+
+func GetSourceTimeZone() string {
+	return entities.CurrentAnalyzer.GetSourceTimeZone().String()
+}
+
+//ApplyDisplayTimeZone sets the time zone of rendered logs according to the DisplayTimeZone setting
+func ApplyDisplayTimeZone() {
+	name := GetConfig().DisplayTimeZone
+	if len(name) == 0 || name == analyzer.SourceTimeZoneSetting {
+		analyzer.SetDisplayLocation(entities.CurrentAnalyzer.GetSourceTimeZone())
+		return
+	}
+	loc, err := analyzer.LoadTimeZone(name)
+	if err != nil {
+		log.Printf("Could not load display time zone '%s', logs are shown in the source time zone. Error: %s", name, err)
+		loc = entities.CurrentAnalyzer.GetSourceTimeZone()
+	}
+	analyzer.SetDisplayLocation(loc)
+}
+
+//ApplyIdeSearchRoots passes folders from IdeSearchRoots setting to the installed IDEs discovery
+func ApplyIdeSearchRoots() {
+	installedIDEs.SetCustomSearchRoots(parseIdeSearchRoots(GetConfig().IdeSearchRoots))
+}
+
+//ApplyRunningIDEPorts passes the port range from settings to the running IDEs detection
+func ApplyRunningIDEPorts() {
+	config := GetConfig()
+	installedIDEs.SetBuiltInServerPorts(config.RunningIDEFirstPort, config.RunningIDELastPort)
+}
+
+//ApplyParsingSettings passes parsing limits from settings to the analyzer. They are used for the logs opened next
+func ApplyParsingSettings() {
+	config := GetConfig()
+	analyzer.SetParsingSettings(analyzer.ParsingSettings{
+		HiddenEntities:           splitLines(config.HiddenEntities),
+		MaxParsedFileSizeMB:      config.MaxParsedFileSizeMB,
+		MaxVisibleFileSizeMB:     config.MaxVisibleFileSizeMB,
+		MaxVisibleRiderLogSizeMB: config.MaxVisibleRiderLogSizeMB,
+		MaxLogEntries:            config.MaxLogEntries,
+		FollowNestedArchives:     config.FollowNestedArchives,
+	})
+}
+
+//IsParsingSetting checks if the setting should be applied with ApplyParsingSettings
+func IsParsingSetting(name string) bool {
+	return analyzer.SliceContains(parsingSettingNames, name) != -1
+}
+
+//splitLines returns trimmed non-empty lines of the setting value
+func splitLines(value string) (lines []string) {
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+//parseIdeSearchRoots splits IdeSearchRoots setting by lines. "~" at the beginning of the folder is replaced with the home directory, environment variables are expanded
+func parseIdeSearchRoots(value string) (roots []string) {
+	for _, root := range splitLines(value) {
+		if root == "~" || strings.HasPrefix(root, "~/") || strings.HasPrefix(root, "~\\") {
+			root = installedIDEs.UserHomeDir() + root[1:]
+		}
+		roots = append(roots, os.ExpandEnv(root))
+	}
+	return roots
+}
+
 func GetLogs() *analyzer.Logs {
 	return entities.CurrentAnalyzer.GetLogs()
 }
+func GetVisibleLogs() analyzer.Logs {
+	return entities.CurrentAnalyzer.GetVisibleLogs()
+}
 func GetStaticInfo() *analyzer.AggregatedStaticInfo {
 	return entities.CurrentAnalyzer.GetStaticInfo()
 }
@@ -45,6 +212,18 @@ func GetOtherFiles() *analyzer.OtherFiles {
 func GetFilters() *analyzer.Filters {
 	return entities.CurrentAnalyzer.GetFilters()
 }
+func GetGCEvents() analyzer.GCEvents {
+	return entities.CurrentAnalyzer.GetGCEvents()
+}
+func GetCrashReports() analyzer.CrashReports {
+	return entities.CurrentAnalyzer.GetCrashReports()
+}
+func GetIndexingHistory() analyzer.IndexingHistory {
+	return entities.CurrentAnalyzer.GetIndexingHistory()
+}
+func GetPluginErrors() analyzer.PluginErrors {
+	return entities.CurrentAnalyzer.GetPluginErrors()
+}
 func GetThreadDumpFolder(dir string) *analyzer.ThreadDump {
 	return entities.CurrentAnalyzer.GetThreadDump(dir)
 }
@@ -58,86 +237,25 @@ func GetIndexingFilePath(path string) string {
 	return ""
 }
 
+//UnzipToTempFodler extracts the archive to a new temp folder. Use InitTempLogDirectory to analyze it, so it is removed afterwards.
 func UnzipToTempFodler(src string) (dest string) {
 	dest, err := ioutil.TempDir("", "IntelliJLogsAnalyzer")
-	r, err := zip.OpenReader(src)
 	if err != nil {
+		log.Printf("Could not create temp folder: %s", err)
 		return ""
 	}
-	defer func() {
-		if err := r.Close(); err != nil {
-			panic(err)
-		}
-	}()
-
-	// Closure to address file descriptors issue with all the deferred .Close() methods
-	extractAndWriteFile := func(f *zip.File) error {
-		archiveFile, err := f.Open()
-		if err != nil {
-			return err
-		}
-		defer func() {
-			if err := archiveFile.Close(); err != nil {
-				panic(err)
-			}
-		}()
-
-		path := filepath.Join(dest, f.Name)
-
-		// Check for ZipSlip (Directory traversal)
-		if !strings.HasPrefix(path, filepath.Clean(dest)+string(os.PathSeparator)) {
-			return fmt.Errorf("illegal file path: %s", path)
-		}
-
-		if f.FileInfo().IsDir() {
-			err = os.MkdirAll(path, 0755)
-			//log.Println(err)
-		} else {
-			err = os.MkdirAll(filepath.Dir(path), 0755)
-			if err != nil {
-				log.Printf("UnzipToTempFodler failed to make dir %s. Error: %s", path, err)
-			}
-			destfile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-			if err != nil {
-				return err
-			}
-			defer func() {
-				if err := destfile.Close(); err != nil {
-					panic(err)
-				}
-				_ = os.Chtimes(destfile.Name(), f.Modified, f.Modified)
-			}()
-
-			_, err = io.Copy(destfile, archiveFile)
-			if err != nil {
-				return err
-			}
-		}
-		return nil
-	}
-
-	for _, f := range r.File {
-		err := extractAndWriteFile(f)
-		if err != nil {
-			return ""
-		}
+	if err := analyzer.ExtractZip(src, dest); err != nil {
+		log.Printf("Could not extract %s: %s", src, err)
+		_ = os.RemoveAll(dest)
+		return ""
 	}
-	entities.CurrentAnalyzer.IsFolderTemp = true
 	log.Println("Temp folder to work in: " + dest)
 	return dest
 }
 
 // Set the Checked values for all FilterEntry elements from frontend
 func SetFilters(f map[string]bool) error {
-	for _, entries := range entities.CurrentAnalyzer.Filters {
-		for i, entry := range entries.Entries {
-			for id, value := range f {
-				if entry.ID == id {
-					entries.Entries[i].Checked = value
-				}
-			}
-		}
-	}
+	entities.CurrentAnalyzer.SetFilters(f)
 	return nil
 }
 
@@ -4,104 +4,379 @@ import (
 	"bytes"
 	"embed"
 	"encoding/json"
+	"fmt"
 	"html/template"
 	"io/ioutil"
 	"log"
+	"log_analyzer/backend/analyzer"
+	"log_analyzer/backend/analyzer/entities"
+	"log_analyzer/backend/analyzer/installedIDEs"
 	"os"
 	"path"
+	"path/filepath"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 )
 
-//go:embed Config.gohtml
+//go:embed Config.gohtml RecentItems.gohtml
 var tmplFS embed.FS
 
+//ConfigVersion is the version of config.json format. Increase it and add a migration to configMigrations when a setting is renamed or its values change meaning
+const ConfigVersion = 3
+
 var (
 	ConfigurationOptions = Config{}
-	defaultConfig        = Config{
-		EditorFontSize: 12,
-		EditorTheme:    "system",
-	}
-	ConfigFileName      = "config.json"
-	ConfigDirectoryName = path.Clean("JetBrains/IntelliJLogAnalyzer")
+	configLoaded         bool
+	configMutex          sync.Mutex
+	ConfigFileName       = "config.json"
+	ConfigDirectoryName  = path.Clean("JetBrains/IntelliJLogAnalyzer")
 )
 
 type Config struct {
+	Version                    int    `json:"Version"`
 	EditorFontSize             int    `json:"EditorFontSize"`
 	EditorTheme                string `json:"EditorTheme"`
 	EditorDefaultSoftWrapState bool   `json:"EditorDefaultSoftWrapState"`
+	DisplayTimeZone            string `json:"DisplayTimeZone"` // DisplayTimeZone is "source" (time zone of the analyzed logs), "local" or IANA time zone name
+	IdeSearchRoots             string `json:"IdeSearchRoots"`  // IdeSearchRoots are folders with IDE installations searched in addition to the standard locations, one per line
+	HiddenEntities             string `json:"HiddenEntities"`  // HiddenEntities are names of log types unchecked in filters by default, one per line
+	MaxParsedFileSizeMB        int    `json:"MaxParsedFileSizeMB"`
+	MaxVisibleFileSizeMB       int    `json:"MaxVisibleFileSizeMB"`
+	MaxVisibleRiderLogSizeMB   int    `json:"MaxVisibleRiderLogSizeMB"`
+	MaxLogEntries              int    `json:"MaxLogEntries"`
+	FollowNestedArchives       bool   `json:"FollowNestedArchives"`
+	KeepExtractedArchives      bool   `json:"KeepExtractedArchives"` // KeepExtractedArchives keeps content of archives from the recent list in the cache folder, so they are reopened without extraction
+	RunningIDEFirstPort        int    `json:"RunningIDEFirstPort"`   // RunningIDEFirstPort and RunningIDELastPort are the ports probed for built-in servers of running IDEs
+	RunningIDELastPort         int    `json:"RunningIDELastPort"`
+}
+
+//SettingDefinition describes the setting stored in the Config field with the same name. Default has the type of the field.
+type SettingDefinition struct {
+	Name     string
+	Default  interface{}
+	Validate func(value interface{}) error // Validate is called with the value converted to the type of the field. Nil if any value is valid
+}
+
+//settingDefinitions are the settings user can change with SaveSetting
+var settingDefinitions = []SettingDefinition{
+	{Name: "EditorFontSize", Default: 12, Validate: intInRange(8, 72)},
+	{Name: "EditorTheme", Default: "system", Validate: oneOf("light", "dark", "system")},
+	{Name: "EditorDefaultSoftWrapState", Default: false},
+	{Name: "DisplayTimeZone", Default: analyzer.SourceTimeZoneSetting, Validate: validDisplayTimeZone},
+	{Name: "IdeSearchRoots", Default: "", Validate: validIdeSearchRoots},
+	{Name: "HiddenEntities", Default: ""},
+	{Name: "MaxParsedFileSizeMB", Default: 0, Validate: intInRange(0, 1024*1024)},
+	{Name: "MaxVisibleFileSizeMB", Default: 0, Validate: intInRange(0, 1024*1024)},
+	{Name: "MaxVisibleRiderLogSizeMB", Default: 47, Validate: intInRange(0, 1024*1024)},
+	{Name: "MaxLogEntries", Default: 0, Validate: intInRange(0, 1000*1000*1000)},
+	{Name: "FollowNestedArchives", Default: false},
+	{Name: "KeepExtractedArchives", Default: false},
+	{Name: "RunningIDEFirstPort", Default: installedIDEs.BuiltInServerFirstPort, Validate: intInRange(1, 65535)},
+	{Name: "RunningIDELastPort", Default: installedIDEs.BuiltInServerLastPort, Validate: intInRange(1, 65535)},
+}
+
+//parsingSettingNames are applied to the analyzer with ApplyParsingSettings
+var parsingSettingNames = []string{"HiddenEntities", "MaxParsedFileSizeMB", "MaxVisibleFileSizeMB", "MaxVisibleRiderLogSizeMB", "MaxLogEntries", "FollowNestedArchives"}
+
+//configMigrations convert the content of config.json of the version to the next version
+var configMigrations = map[int]func(raw map[string]interface{}){
+	// Version 1 had no Version field and stored zero values of settings that were never set
+	1: func(raw map[string]interface{}) {
+		for name, zero := range map[string]interface{}{"EditorFontSize": 0.0, "EditorTheme": "", "DisplayTimeZone": ""} {
+			if raw[name] == zero {
+				delete(raw, name)
+			}
+		}
+	},
+	// Version 2 hid files bigger than 47 MB of every log type by default, now only Rider backend logs are hidden by their size
+	2: func(raw map[string]interface{}) {
+		if raw["MaxVisibleFileSizeMB"] == 47.0 {
+			delete(raw, "MaxVisibleFileSizeMB")
+		}
+	},
 }
 
-func GetConfig() *Config {
-	if ConfigurationOptions != *new(Config) {
-		return &ConfigurationOptions
-	} else {
+func init() {
+	for _, definition := range settingDefinitions {
+		field, found := reflect.TypeOf(Config{}).FieldByName(definition.Name)
+		if !found || reflect.TypeOf(definition.Default) != field.Type {
+			panic(fmt.Sprintf("Setting %s does not match the field of Config", definition.Name))
+		}
+	}
+	for version := 1; version < ConfigVersion; version++ {
+		if configMigrations[version] == nil {
+			panic(fmt.Sprintf("Migration of config from version %d is not defined", version))
+		}
+	}
+}
+
+//GetConfig returns a copy of the settings, they are changed with SaveSetting only
+func GetConfig() Config {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	loadConfig()
+	return ConfigurationOptions
+}
+
+//loadConfig reads config file on the first use. configMutex should be held
+func loadConfig() {
+	if !configLoaded {
 		ConfigurationOptions = generateConfig()
+		configLoaded = true
 	}
-	return &ConfigurationOptions
 }
+
+// settingsScreen is rendered by Config.gohtml: settings and names of log types that could be hidden by default
+type settingsScreen struct {
+	Config
+	Entities []entityVisibility
+}
+
+type entityVisibility struct {
+	Name   string
+	Hidden bool
+}
+
 func GetSettingsScreenHTML() string {
 	config := GetConfig()
+	screen := settingsScreen{Config: config}
+	hidden := splitLines(config.HiddenEntities)
+	for _, name := range entities.CurrentAnalyzer.GetDynamicEntityNames() {
+		screen.Entities = append(screen.Entities, entityVisibility{Name: name, Hidden: analyzer.SliceContains(hidden, name) != -1})
+	}
 	var tpl bytes.Buffer
 	t := template.Must(template.New("Config.gohtml").
 		ParseFS(tmplFS, "Config.gohtml"))
-	err := t.Execute(&tpl, config)
+	err := t.Execute(&tpl, screen)
 	if err != nil {
 		log.Printf("Template Config.gohtml execution failed. Error: %s", err.Error())
 	}
 	return tpl.String()
 }
-func (c *Config) saveConfig() {
+
+//saveConfig writes config to a temporary file and renames it, so config.json is never left half-written
+func (c *Config) saveConfig() error {
 	configPath := getConfigFilePath()
-	if !FileExists(configPath) {
-		createConfig(configPath)
+	if err := createConfigDirectory(filepath.Dir(configPath)); err != nil {
+		return err
 	}
-	configFileContent, err := json.Marshal(c)
+	configFileContent, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
-		log.Println("Error while marshalling config file content: ", err)
+		return err
+	}
+	return writeFileAtomically(configPath, configFileContent)
+}
+
+//SaveSetting validates the value, sets it to the setting and saves config. Config is not changed if the value is invalid or could not be saved.
+func SaveSetting(id string, value interface{}) error {
+	log.Printf("Saving setting '%s' with value '%v'", id, value)
+	definition, found := findSettingDefinition(id)
+	if !found {
+		return fmt.Errorf("unknown setting %s", id)
+	}
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	loadConfig()
+	updated := ConfigurationOptions
+	if err := updated.setSetting(definition, value); err != nil {
+		return err
+	}
+	if err := updated.saveConfig(); err != nil {
+		return fmt.Errorf("could not save settings: %s", err)
+	}
+	ConfigurationOptions = updated
+	return nil
+}
+
+func findSettingDefinition(name string) (SettingDefinition, bool) {
+	for _, definition := range settingDefinitions {
+		if definition.Name == name {
+			return definition, true
+		}
 	}
-	err = ioutil.WriteFile(configPath, configFileContent, 0644)
+	return SettingDefinition{}, false
+}
+
+//setSetting converts the value to the type of the setting and validates it
+func (c *Config) setSetting(definition SettingDefinition, value interface{}) error {
+	field := reflect.ValueOf(c).Elem().FieldByName(definition.Name)
+	converted, err := convertSettingValue(value, field.Type())
 	if err != nil {
-		log.Println("Error while writing config file: ", err)
+		return fmt.Errorf("setting %s: %s", definition.Name, err)
+	}
+	if definition.Validate != nil {
+		if err := definition.Validate(converted.Interface()); err != nil {
+			return fmt.Errorf("setting %s: %s", definition.Name, err)
+		}
 	}
+	field.Set(converted)
+	return nil
 }
-func (c *Config) SaveSetting(id string, value interface{}) {
-	log.Printf("Saving setting '%s' with value '%v'", id, value)
-	aValue := reflect.ValueOf(value)
-	fields := reflect.VisibleFields(reflect.TypeOf(Config{}))
-	for _, field := range fields {
-		if aValue.CanConvert(field.Type) {
-			if field.Name == id {
-				reflect.ValueOf(c).Elem().FieldByName(field.Name).Set(aValue.Convert(field.Type))
+
+//convertSettingValue converts values coming from JSON (numbers are float64) or from UI (strings) to the type of the setting
+func convertSettingValue(value interface{}, to reflect.Type) (reflect.Value, error) {
+	switch to.Kind() {
+	case reflect.Int:
+		switch v := value.(type) {
+		case int:
+			return reflect.ValueOf(v), nil
+		case float64:
+			if v == float64(int(v)) {
+				return reflect.ValueOf(int(v)), nil
+			}
+		case string:
+			if i, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+				return reflect.ValueOf(i), nil
+			}
+		}
+		return reflect.Value{}, fmt.Errorf("%v is not an integer", value)
+	case reflect.Bool:
+		switch v := value.(type) {
+		case bool:
+			return reflect.ValueOf(v), nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return reflect.ValueOf(b), nil
+			}
+		}
+		return reflect.Value{}, fmt.Errorf("%v is not a boolean", value)
+	case reflect.String:
+		if v, ok := value.(string); ok {
+			return reflect.ValueOf(v), nil
+		}
+		return reflect.Value{}, fmt.Errorf("%v is not a string", value)
+	}
+	return reflect.Value{}, fmt.Errorf("settings of type %s are not supported", to)
+}
+
+func intInRange(min int, max int) func(value interface{}) error {
+	return func(value interface{}) error {
+		if v := value.(int); v < min || v > max {
+			return fmt.Errorf("%d is out of range %d-%d", v, min, max)
+		}
+		return nil
+	}
+}
+
+func oneOf(options ...string) func(value interface{}) error {
+	return func(value interface{}) error {
+		for _, option := range options {
+			if value.(string) == option {
+				return nil
 			}
 		}
+		return fmt.Errorf("\"%s\" is not one of %s", value, strings.Join(options, ", "))
 	}
-	c.saveConfig()
+}
+
+func validDisplayTimeZone(value interface{}) error {
+	if name := value.(string); name != analyzer.SourceTimeZoneSetting {
+		if _, err := analyzer.LoadTimeZone(name); err != nil {
+			return fmt.Errorf("unknown time zone \"%s\"", name)
+		}
+	}
+	return nil
+}
+
+func validIdeSearchRoots(value interface{}) error {
+	for _, root := range parseIdeSearchRoots(value.(string)) {
+		if !filepath.IsAbs(root) {
+			return fmt.Errorf("\"%s\" is not an absolute path", root)
+		}
+	}
+	return nil
+}
+
+func newDefaultConfig() Config {
+	config := Config{Version: ConfigVersion}
+	for _, definition := range settingDefinitions {
+		reflect.ValueOf(&config).Elem().FieldByName(definition.Name).Set(reflect.ValueOf(definition.Default))
+	}
+	return config
 }
 
 func generateConfig() Config {
 	configPath := getConfigFilePath()
-	if !FileExists(configPath) {
+	content, err := ioutil.ReadFile(configPath)
+	if err != nil {
 		log.Printf("Could not open configuration file: %s \n Using default config", configPath)
+		return newDefaultConfig()
+	}
+	config, changed, err := parseConfig(content)
+	if err != nil {
+		log.Printf("Could not parse configuration file %s, using default config. Error: %s", configPath, err)
+		return newDefaultConfig()
 	}
-	configValues := getConfigValues(configPath)
-	if configValues != *new(Config) {
-		return configValues
-	} else {
-		return defaultConfig
+	if changed {
+		if err := config.saveConfig(); err != nil {
+			log.Printf("Could not save migrated configuration file %s: %s", configPath, err)
+		}
 	}
+	return config
 }
-func getConfigValues(configPath string) Config {
-	configFile, err := os.Open(configPath)
-	if err != nil {
-		log.Printf("Could not open configuration file: %s", configPath)
-		return *new(Config)
+
+//parseConfig migrates the content to ConfigVersion and reads it over the defaults. Invalid values are reset to defaults.
+//changed is true if the content should be saved again. Content of newer versions is never saved again, it would drop settings unknown to this version.
+func parseConfig(content []byte) (config Config, changed bool, err error) {
+	raw := make(map[string]interface{})
+	if err = json.Unmarshal(content, &raw); err != nil {
+		return config, false, err
+	}
+	version := 1
+	if v, ok := raw["Version"].(float64); ok {
+		version = int(v)
+	}
+	newer := version > ConfigVersion
+	if newer {
+		log.Printf("Configuration file has version %d, newer than supported %d. Unknown settings are ignored", version, ConfigVersion)
+	}
+	for ; version < ConfigVersion; version++ {
+		log.Printf("Migrating configuration file from version %d", version)
+		configMigrations[version](raw)
+		changed = true
+	}
+	config = newDefaultConfig()
+	for _, definition := range settingDefinitions {
+		value, found := raw[definition.Name]
+		if !found {
+			continue
+		}
+		if err := config.setSetting(definition, value); err != nil {
+			log.Printf("Configuration file: %s. Default value is used", err)
+			changed = !newer
+		}
 	}
-	defer configFile.Close()
+	if newer {
+		config.Version = version
+	}
+	return config, changed, nil
+}
 
-	byteValue, _ := ioutil.ReadAll(configFile)
-	var config Config
-	json.Unmarshal(byteValue, &config)
-	return config
+//writeFileAtomically writes content to a temporary file in the same folder and replaces the file with it
+func writeFileAtomically(path string, content []byte) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 func getConfigFilePath() string {
 	return getConfigDir() + string(os.PathSeparator) + ConfigDirectoryName + string(os.PathSeparator) + ConfigFileName
@@ -114,35 +389,6 @@ func getConfigDir() string {
 	}
 	return configPath
 }
-func createConfig(configFilePath string) {
-	configDir := path.Dir(configFilePath)
-	if err, ConfigDirectoryExists := os.Stat(configDir); ConfigDirectoryExists == nil {
-		log.Printf("Creating configuration file in %s", configDir)
-		createConfigFile(configFilePath)
-	} else {
-		log.Printf("Could not find configuration directory: %v", err)
-		err := createConfigDirectory(configDir)
-		if err == nil {
-			createConfigFile(configFilePath)
-		} else {
-			log.Printf("Could not create configuration directory: %v", err)
-		}
-	}
-}
-func createConfigFile(configFilePath string) {
-	configFile, err := os.Create(configFilePath)
-	defer func(configFile *os.File) {
-		err := configFile.Close()
-		if err != nil {
-			log.Printf("Could not close configuration file: %v", err)
-		}
-	}(configFile)
-
-	if err != nil {
-		log.Printf("Could not create configuration file: %v", err)
-	}
-	log.Printf("Successfully created configuration file: %s", configFilePath)
-}
 func createConfigDirectory(path string) error {
 	err := os.MkdirAll(path, os.ModePerm)
 	if err != nil {
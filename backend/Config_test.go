@@ -0,0 +1,84 @@
+package backend
+
+import (
+	"log_analyzer/backend/analyzer"
+	"testing"
+)
+
+func TestParseConfigMigratesVersion1(t *testing.T) {
+	config, changed, err := parseConfig([]byte(`{"EditorFontSize": 0, "EditorTheme": "", "DisplayTimeZone": "", "EditorDefaultSoftWrapState": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Error("migrated config should be saved again")
+	}
+	if config.Version != ConfigVersion {
+		t.Errorf("expected version %d, got %d", ConfigVersion, config.Version)
+	}
+	if config.EditorFontSize != 12 || config.EditorTheme != "system" || config.DisplayTimeZone != analyzer.SourceTimeZoneSetting {
+		t.Errorf("zero values of version 1 should be replaced with defaults, got %+v", config)
+	}
+	if !config.EditorDefaultSoftWrapState {
+		t.Error("settings set by user should be kept")
+	}
+}
+
+func TestParseConfigMigratesVersion2(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		visibleMB int
+	}{
+		{"old default is dropped", `{"Version": 2, "MaxVisibleFileSizeMB": 47}`, 0},
+		{"value set by user is kept", `{"Version": 2, "MaxVisibleFileSizeMB": 100}`, 100},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config, changed, err := parseConfig([]byte(test.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !changed || config.Version != ConfigVersion {
+				t.Errorf("config should be migrated to version %d, got version %d, changed %v", ConfigVersion, config.Version, changed)
+			}
+			if config.MaxVisibleFileSizeMB != test.visibleMB {
+				t.Errorf("expected MaxVisibleFileSizeMB %d, got %d", test.visibleMB, config.MaxVisibleFileSizeMB)
+			}
+		})
+	}
+}
+
+//TestParseConfigKeepsFalseSettings checks that config with every boolean setting false is not replaced with defaults
+func TestParseConfigKeepsFalseSettings(t *testing.T) {
+	content := `{"Version": 3, "EditorFontSize": 14, "EditorTheme": "dark", "EditorDefaultSoftWrapState": false, "FollowNestedArchives": false, "KeepExtractedArchives": false}`
+	config, changed, err := parseConfig([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Error("valid config of the current version should not be saved again")
+	}
+	if config.EditorFontSize != 14 || config.EditorTheme != "dark" || config.EditorDefaultSoftWrapState || config.FollowNestedArchives || config.KeepExtractedArchives {
+		t.Errorf("settings are not kept: %+v", config)
+	}
+	if config.RunningIDEFirstPort != newDefaultConfig().RunningIDEFirstPort {
+		t.Errorf("missing settings should get defaults, got %+v", config)
+	}
+}
+
+func TestParseConfigOfNewerVersion(t *testing.T) {
+	config, changed, err := parseConfig([]byte(`{"Version": 100, "EditorFontSize": 1, "NewSetting": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Error("config of newer version should not be saved again")
+	}
+	if config.Version != 100 {
+		t.Errorf("version of newer config should be kept, got %d", config.Version)
+	}
+	if config.EditorFontSize != 12 {
+		t.Errorf("invalid value should be replaced with default, got %d", config.EditorFontSize)
+	}
+}
@@ -0,0 +1,56 @@
+package backend
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"log_analyzer/backend/analyzer/entities"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	CustomEntitiesDirectoryName = "entities"
+	loadCustomEntitiesOnce      sync.Once
+)
+
+//LoadCustomEntities registers log types declared in *.json files of the "entities" folder inside the configuration directory.
+//Every file contains one CustomEntityDefinition. Malformed definitions are skipped.
+func LoadCustomEntities() {
+	loadCustomEntitiesOnce.Do(func() {
+		dir := getCustomEntitiesDir()
+		files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil || len(files) == 0 {
+			log.Printf("No custom entities found in %s", dir)
+			return
+		}
+		for _, file := range files {
+			definition, err := readCustomEntityDefinition(file)
+			if err != nil {
+				log.Printf("Could not read custom entity definition %s: %s", file, err)
+				continue
+			}
+			entity, err := entities.NewCustomEntity(definition)
+			if err != nil {
+				log.Printf("Custom entity definition %s is skipped: %s", file, err)
+				continue
+			}
+			entities.CurrentAnalyzer.AddDynamicEntity(entity)
+			log.Printf("Loaded custom entity \"%s\" from %s", entity.Name, file)
+		}
+	})
+}
+
+func readCustomEntityDefinition(path string) (definition entities.CustomEntityDefinition, err error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return definition, err
+	}
+	err = json.Unmarshal(content, &definition)
+	return definition, err
+}
+
+func getCustomEntitiesDir() string {
+	return getConfigDir() + string(os.PathSeparator) + ConfigDirectoryName + string(os.PathSeparator) + CustomEntitiesDirectoryName
+}
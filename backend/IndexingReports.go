@@ -0,0 +1,135 @@
+package backend
+
+import (
+	"io/ioutil"
+	"log"
+	"log_analyzer/backend/analyzer/entities"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+//IndexingReportURLPrefix is the path indexing reports are served from by IndexingReportHandler, for example /indexing-report/<project folder>/report.html
+const IndexingReportURLPrefix = "/indexing-report/"
+
+var reportLinkRegex = regexp.MustCompile(`(?i)\b(href|src)\s*=\s*"([^"]*)"`)
+
+//GetIndexingReportURL returns the in-app URL of the diagnostic-<date>.html report
+func GetIndexingReportURL(fileName string) string {
+	path := GetIndexingFilePath(fileName)
+	if len(path) == 0 {
+		return ""
+	}
+	return indexingReportURL(filepath.Dir(path), filepath.Base(path))
+}
+
+//GetIndexingSummaryURL returns the in-app URL of the report.html of the project the report belongs to
+func GetIndexingSummaryURL(fileName string) string {
+	path := GetIndexingFilePath(fileName)
+	if len(path) == 0 {
+		return ""
+	}
+	return indexingReportURL(filepath.Dir(path), "report.html")
+}
+
+func indexingReportURL(dir string, file string) string {
+	return IndexingReportURLPrefix + url.PathEscape(filepath.Base(dir)) + "/" + url.PathEscape(file)
+}
+
+//IndexingReportHandler serves files of indexing-diagnostic folders of the analyzed bundle.
+//Only files inside the project folders of indexing diagnostic are served, links of html files are rewritten to stay inside the folder.
+type IndexingReportHandler struct{}
+
+func NewIndexingReportHandler() http.Handler {
+	return IndexingReportHandler{}
+}
+
+func (h IndexingReportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, IndexingReportURLPrefix) {
+		http.NotFound(w, r)
+		return
+	}
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, IndexingReportURLPrefix), "/", 2)
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	dir := findIndexingReportsFolder(parts[0])
+	if len(dir) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	path := filepath.Join(dir, filepath.FromSlash(parts[1]))
+	if !isInsideFolder(dir, path) {
+		log.Printf("Access to %s outside of indexing diagnostic folder is denied", path)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if strings.HasPrefix(contentType, "text/html") || len(contentType) == 0 && filepath.Ext(path) == ".html" {
+		content = rewriteIndexingReportLinks(content, dir)
+		contentType = "text/html; charset=utf-8"
+	}
+	if len(contentType) > 0 {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Header().Set("Content-Security-Policy", "default-src 'self' 'unsafe-inline' data:; connect-src 'none'; form-action 'none'")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, _ = w.Write(content)
+}
+
+//findIndexingReportsFolder returns the folder of the analyzed bundle with indexing reports of the project
+func findIndexingReportsFolder(name string) string {
+	for _, path := range entities.CurrentAnalyzer.GetIndexingFilesList() {
+		if filepath.Base(filepath.Dir(path)) == name {
+			return filepath.Dir(path)
+		}
+	}
+	return ""
+}
+
+func isInsideFolder(dir string, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator)) && !filepath.IsAbs(rel)
+}
+
+//rewriteIndexingReportLinks makes links to local files relative, so they are served by IndexingReportHandler. Links to files outside the folder are removed.
+func rewriteIndexingReportLinks(html []byte, dir string) []byte {
+	return reportLinkRegex.ReplaceAllFunc(html, func(match []byte) []byte {
+		groups := reportLinkRegex.FindSubmatch(match)
+		return []byte(string(groups[1]) + `="` + rewriteIndexingReportLink(string(groups[2]), dir) + `"`)
+	})
+}
+
+func rewriteIndexingReportLink(link string, dir string) string {
+	local := link
+	switch {
+	case strings.HasPrefix(link, "file:"):
+		local = strings.TrimPrefix(strings.TrimPrefix(link, "file:"), "//")
+		// file:///C:/path on Windows
+		if len(local) > 2 && local[0] == '/' && local[2] == ':' {
+			local = local[1:]
+		}
+		if unescaped, err := url.PathUnescape(local); err == nil {
+			local = unescaped
+		}
+	case filepath.IsAbs(link) || strings.HasPrefix(link, "/"):
+	default:
+		return link
+	}
+	path := filepath.Clean(filepath.FromSlash(local))
+	if !isInsideFolder(dir, path) {
+		return "#"
+	}
+	rel, _ := filepath.Rel(dir, path)
+	return filepath.ToSlash(rel)
+}
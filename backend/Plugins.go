@@ -0,0 +1,54 @@
+package backend
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"log_analyzer/backend/analyzer"
+	"os"
+)
+
+var (
+	PluginCatalogFileName  = "plugins-catalog.json"
+	PluginPackagesFileName = "plugin-packages.json"
+)
+
+//LoadPluginCatalog loads the offline snapshot of the marketplace from the configuration directory, if it exists.
+//Plugins found in logs are annotated as outdated, incompatible or unknown with its help.
+func LoadPluginCatalog() {
+	path := getPluginCatalogPath()
+	if !FileExists(path) {
+		log.Printf("Plugin catalog %s not found, plugins will not be checked", path)
+		return
+	}
+	catalog, err := analyzer.LoadPluginCatalog(path)
+	if err != nil {
+		log.Printf("Could not load plugin catalog %s: %s", path, err)
+		return
+	}
+	analyzer.SetPluginCatalog(catalog)
+	log.Printf("Loaded %d plugins from catalog %s", len(catalog.Plugins), path)
+}
+
+func getPluginCatalogPath() string {
+	return getConfigDir() + string(os.PathSeparator) + ConfigDirectoryName + string(os.PathSeparator) + PluginCatalogFileName
+}
+
+//LoadPluginPackages loads package prefixes of plugins from the configuration directory, if the file exists.
+//The file is a JSON object mapping package prefix to plugin name, for example {"com.example.myplugin": "My Plugin"}.
+//Exceptions with frames from these packages are attributed to the plugin.
+func LoadPluginPackages() {
+	path := getConfigDir() + string(os.PathSeparator) + ConfigDirectoryName + string(os.PathSeparator) + PluginPackagesFileName
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Printf("Plugin packages %s not found, only \"Plugin to blame\" lines are used to find plugins causing errors", path)
+		return
+	}
+	packages := make(map[string]string)
+	if err = json.Unmarshal(content, &packages); err != nil {
+		log.Printf("Could not load plugin packages %s: %s", path, err)
+		return
+	}
+	analyzer.SetPluginPackages(packages)
+	log.Printf("Loaded %d plugin packages from %s", len(packages), path)
+}
@@ -0,0 +1,344 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"log"
+	"log_analyzer/backend/analyzer"
+	"log_analyzer/backend/analyzer/entities"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Kinds of recent items
+const (
+	RecentFolder  = "folder"
+	RecentArchive = "archive"
+	RecentIDE     = "ide"
+)
+
+//maxRecentItems is the length of the recent list. Extracted archives of items dropped from the list are removed.
+const maxRecentItems = 15
+
+var (
+	RecentItemsFileName     = "recent.json"
+	ArchivesCacheFolderName = "archives"
+	recentItems             RecentItems
+	recentItemsLoaded       bool
+	currentRecentItem       = -1 // currentRecentItem is the index of analyzed item in recentItems, -1 if analyzed logs are not remembered
+	recentItemsMutex        sync.Mutex
+)
+
+//RecentItem is the folder, archive or installed IDE analyzed before together with the state of its analysis
+type RecentItem struct {
+	Kind                     string
+	Path                     string    // Path is the folder, the archive or the logs directory of the installed IDE
+	Name                     string    // Name is the name and version of the installed IDE
+	IncludeSystemDiagnostics bool      // IncludeSystemDiagnostics is used for installed IDEs
+	ExtractedPath            string    // ExtractedPath is the kept content of the archive. Empty if it was extracted to a temp folder
+	ArchiveModified          time.Time // ArchiveModified is the modification time of the archive when it was extracted to ExtractedPath
+	Opened                   time.Time
+	Workspace                WorkspaceState
+}
+
+//WorkspaceState is what user changed while analyzing the logs. It is restored when the logs are opened again.
+type WorkspaceState struct {
+	Filters    map[string]bool // Filters are Checked values of filters keyed by relative path, see analyzer.GetFilterStates
+	Search     string
+	ScrollLine int
+	Notes      string
+}
+
+type RecentItems []RecentItem
+
+//Title is the name shown in the recent list: the IDE name, or the folder or archive name
+func (r RecentItem) Title() string {
+	if len(r.Name) > 0 {
+		return r.Name
+	}
+	return filepath.Base(r.Path)
+}
+
+//IsCached checks if the archive is reopened from ExtractedPath without extraction
+func (r RecentItem) IsCached() bool {
+	return r.Kind == RecentArchive && len(r.ExtractedPath) > 0 && FileExists(r.ExtractedPath)
+}
+
+func (r RecentItem) isSame(other RecentItem) bool {
+	return r.Kind == other.Kind && filepath.Clean(r.Path) == filepath.Clean(other.Path)
+}
+
+func (r RecentItems) ConvertToHTML() string {
+	var tpl bytes.Buffer
+	t := template.Must(template.New("RecentItems.gohtml").
+		ParseFS(tmplFS, "RecentItems.gohtml"))
+	err := t.Execute(&tpl, r)
+	if err != nil {
+		log.Printf("Template RecentItems.gohtml execution failed. Error: %s", err.Error())
+	}
+	return tpl.String()
+}
+
+//GetRecentItems returns a copy of the recent list, the latest opened first
+func GetRecentItems() RecentItems {
+	recentItemsMutex.Lock()
+	defer recentItemsMutex.Unlock()
+	loadRecentItems()
+	return append(RecentItems{}, recentItems...)
+}
+
+//OpenFolder analyzes the folder and adds it to the recent list
+func OpenFolder(path string, ctx *context.Context) error {
+	if err := initLogDirectories(ctx, path, false); err != nil {
+		return err
+	}
+	rememberRecentItem(RecentItem{Kind: RecentFolder, Path: path})
+	return nil
+}
+
+//OpenArchive extracts the archive and analyzes its content. If KeepExtractedArchives is enabled, the content is kept in the cache folder
+//and reused the next time the archive is opened unless the archive was modified. Returns the folder with the content.
+func OpenArchive(zipPath string, ctx *context.Context) (string, error) {
+	item := RecentItem{Kind: RecentArchive, Path: zipPath}
+	previous, _ := findRecentItem(item)
+	if GetConfig().KeepExtractedArchives {
+		if previous.IsCached() && !isArchiveModified(previous) {
+			log.Printf("Opening %s from cache %s", zipPath, previous.ExtractedPath)
+			item.ExtractedPath, item.ArchiveModified = previous.ExtractedPath, previous.ArchiveModified
+		} else if dest, modified, err := extractToArchivesCache(zipPath); err == nil {
+			item.ExtractedPath, item.ArchiveModified = dest, modified
+		} else {
+			log.Printf("Could not keep extracted archive %s, extracting it to temp folder. Error: %s", zipPath, err)
+		}
+	}
+	if previous.IsCached() && previous.ExtractedPath != item.ExtractedPath {
+		removeExtractedArchive(previous.ExtractedPath)
+	}
+	if len(item.ExtractedPath) > 0 {
+		if err := initLogDirectories(ctx, item.ExtractedPath, false); err != nil {
+			if item.ExtractedPath != previous.ExtractedPath {
+				removeExtractedArchive(item.ExtractedPath)
+			}
+			return "", err
+		}
+		rememberRecentItem(item)
+		return item.ExtractedPath, nil
+	}
+	dest := UnzipToTempFodler(zipPath)
+	if len(dest) == 0 {
+		return "", fmt.Errorf("could not extract %s", zipPath)
+	}
+	if err := InitTempLogDirectory(dest, ctx); err != nil {
+		return "", err
+	}
+	rememberRecentItem(item)
+	return dest, nil
+}
+
+//OpenRecentItem analyzes the item of the recent list again and restores its filters
+func OpenRecentItem(index int, ctx *context.Context) error {
+	recentItemsMutex.Lock()
+	loadRecentItems()
+	if index < 0 || index >= len(recentItems) {
+		recentItemsMutex.Unlock()
+		return fmt.Errorf("recent item %d does not exist", index)
+	}
+	item := recentItems[index]
+	recentItemsMutex.Unlock()
+	switch item.Kind {
+	case RecentFolder:
+		return OpenFolder(item.Path, ctx)
+	case RecentArchive:
+		if !FileExists(item.Path) && !item.IsCached() {
+			return fmt.Errorf("archive %s does not exist", item.Path)
+		}
+		_, err := OpenArchive(item.Path, ctx)
+		return err
+	case RecentIDE:
+		return OpenInstalledIDE(item.Path, item.IncludeSystemDiagnostics, ctx)
+	}
+	return fmt.Errorf("unknown kind of recent item: %s", item.Kind)
+}
+
+//RemoveRecentItem removes the item from the recent list together with its extracted archive
+func RemoveRecentItem(index int) error {
+	recentItemsMutex.Lock()
+	defer recentItemsMutex.Unlock()
+	loadRecentItems()
+	if index < 0 || index >= len(recentItems) {
+		return fmt.Errorf("recent item %d does not exist", index)
+	}
+	if index == currentRecentItem {
+		return errors.New("analyzed logs could not be removed from the recent list")
+	}
+	removeExtractedArchive(recentItems[index].ExtractedPath)
+	recentItems = append(recentItems[:index], recentItems[index+1:]...)
+	if currentRecentItem > index {
+		currentRecentItem--
+	}
+	return saveRecentItems()
+}
+
+//GetWorkspaceState returns the saved state of analyzed logs. found is false if analyzed logs are not in the recent list, for example dropped files
+func GetWorkspaceState() (state WorkspaceState, found bool) {
+	recentItemsMutex.Lock()
+	defer recentItemsMutex.Unlock()
+	if currentRecentItem < 0 {
+		return state, false
+	}
+	return recentItems[currentRecentItem].Workspace, true
+}
+
+//SaveWorkspaceState saves search, scroll position, notes and current filters of analyzed logs. Nothing is saved if they are not in the recent list.
+func SaveWorkspaceState(search string, scrollLine int, notes string) error {
+	filters := entities.CurrentAnalyzer.GetFilterStates()
+	recentItemsMutex.Lock()
+	defer recentItemsMutex.Unlock()
+	if currentRecentItem < 0 {
+		return nil
+	}
+	recentItems[currentRecentItem].Workspace = WorkspaceState{
+		Filters:    filters,
+		Search:     search,
+		ScrollLine: scrollLine,
+		Notes:      notes,
+	}
+	return saveRecentItems()
+}
+
+//forgetCurrentRecentItem is called when other logs are opened, so the state of previous logs is not overwritten
+func forgetCurrentRecentItem() {
+	recentItemsMutex.Lock()
+	defer recentItemsMutex.Unlock()
+	currentRecentItem = -1
+}
+
+//rememberRecentItem moves the item to the top of the recent list and restores filters saved for it
+func rememberRecentItem(item RecentItem) {
+	recentItemsMutex.Lock()
+	loadRecentItems()
+	item.Opened = time.Now()
+	items := RecentItems{item}
+	for _, existing := range recentItems {
+		if existing.isSame(item) {
+			items[0].Workspace = existing.Workspace
+		} else {
+			items = append(items, existing)
+		}
+	}
+	for len(items) > maxRecentItems {
+		removeExtractedArchive(items[len(items)-1].ExtractedPath)
+		items = items[:len(items)-1]
+	}
+	recentItems = items
+	currentRecentItem = 0
+	if err := saveRecentItems(); err != nil {
+		log.Printf("Could not save recent items: %s", err)
+	}
+	filters := items[0].Workspace.Filters
+	recentItemsMutex.Unlock()
+	entities.CurrentAnalyzer.RestoreFilterStates(filters)
+}
+
+func findRecentItem(item RecentItem) (RecentItem, bool) {
+	recentItemsMutex.Lock()
+	defer recentItemsMutex.Unlock()
+	loadRecentItems()
+	for _, existing := range recentItems {
+		if existing.isSame(item) {
+			return existing, true
+		}
+	}
+	return RecentItem{}, false
+}
+
+//loadRecentItems reads the recent list from the config directory once. Should be called with recentItemsMutex locked
+func loadRecentItems() {
+	if recentItemsLoaded {
+		return
+	}
+	recentItemsLoaded = true
+	content, err := ioutil.ReadFile(getRecentItemsFilePath())
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(content, &recentItems); err != nil {
+		log.Printf("Could not parse recent items file %s: %s", getRecentItemsFilePath(), err)
+		recentItems = nil
+	}
+}
+
+//saveRecentItems should be called with recentItemsMutex locked
+func saveRecentItems() error {
+	path := getRecentItemsFilePath()
+	if err := createConfigDirectory(filepath.Dir(path)); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(recentItems, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(path, content)
+}
+
+func isArchiveModified(item RecentItem) bool {
+	info, err := os.Stat(item.Path)
+	return err == nil && !info.ModTime().Equal(item.ArchiveModified)
+}
+
+//extractToArchivesCache extracts the archive to a new folder inside the archives cache and returns it with modification time of the archive
+func extractToArchivesCache(zipPath string) (dest string, modified time.Time, err error) {
+	info, err := os.Stat(zipPath)
+	if err != nil {
+		return "", modified, err
+	}
+	if err = os.MkdirAll(getArchivesCacheDir(), os.ModePerm); err != nil {
+		return "", modified, err
+	}
+	dest, err = os.MkdirTemp(getArchivesCacheDir(), strings.TrimSuffix(filepath.Base(zipPath), filepath.Ext(zipPath))+"-")
+	if err != nil {
+		return "", modified, err
+	}
+	if err = analyzer.ExtractZip(zipPath, dest); err != nil {
+		_ = os.RemoveAll(dest)
+		return "", modified, err
+	}
+	log.Printf("Extracted %s to cache folder %s", zipPath, dest)
+	return dest, info.ModTime(), nil
+}
+
+//removeExtractedArchive removes the folder if it is inside the archives cache
+func removeExtractedArchive(path string) {
+	if len(path) == 0 {
+		return
+	}
+	rel, err := filepath.Rel(getArchivesCacheDir(), path)
+	if err != nil || rel == "." || filepath.IsAbs(rel) || strings.HasPrefix(rel, "..") {
+		log.Printf("Folder %s is not inside archives cache, it is not removed", path)
+		return
+	}
+	if err := os.RemoveAll(path); err != nil {
+		log.Printf("Removing folder '%s' failed. Error: %s", path, err)
+	}
+}
+
+func getRecentItemsFilePath() string {
+	return getConfigDir() + string(os.PathSeparator) + ConfigDirectoryName + string(os.PathSeparator) + RecentItemsFileName
+}
+
+//getArchivesCacheDir returns the folder kept archives are extracted to
+func getArchivesCacheDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	return filepath.Join(cacheDir, ConfigDirectoryName, ArchivesCacheFolderName)
+}
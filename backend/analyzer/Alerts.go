@@ -0,0 +1,184 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//severityRanks orders severities used by log entities, the higher the more severe
+var severityRanks = map[string]int{
+	"TRACE":   0,
+	"FINE":    1,
+	"DEBUG":   1,
+	"INFO":    2,
+	"WARN":    3,
+	"WARNING": 3,
+	"ERROR":   4,
+	"SEVERE":  4,
+	"EXCPT":   4,
+	"FATAL":   5,
+}
+
+//AlertRule describes log entries or files that should be reported while logs are updated live.
+//Conditions of the rule are combined, empty conditions are not checked. A rule with PathPattern matches files added to the folder, other rules match log entries.
+type AlertRule struct {
+	Name        string `json:"Name"`
+	MinSeverity string `json:"MinSeverity"` // MinSeverity matches entries of this or a higher severity, for example "ERROR"
+	Pattern     string `json:"Pattern"`     // Pattern is a regular expression matched against the text of the entry
+	Entity      string `json:"Entity"`      // Entity limits the rule to entries of the log type, for example "Idea Log". It is the name shown in filters, case is ignored
+	PathPattern string `json:"PathPattern"` // PathPattern is a regular expression matched against the name of a new file or folder, for example "^threadDumps-freeze-"
+	pattern     *regexp.Regexp
+	pathPattern *regexp.Regexp
+}
+
+//Alert is raised when a rule matches during live update
+type Alert struct {
+	Rule     string
+	Time     time.Time
+	Severity string
+	Text     string // Text is the first line of the matched entry or the name of the matched file
+}
+
+//alertRules are checked for every entry and file added during live update
+var alertRules []AlertRule
+
+//SetAlertRules validates and compiles the rules. Invalid rules are skipped and reported in the returned error.
+func SetAlertRules(rules []AlertRule) error {
+	var valid []AlertRule
+	var problems []string
+	for _, rule := range rules {
+		if err := rule.compile(); err != nil {
+			problems = append(problems, fmt.Sprintf("rule \"%s\": %s", rule.Name, err))
+			continue
+		}
+		valid = append(valid, rule)
+	}
+	alertRules = valid
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (rule *AlertRule) compile() (err error) {
+	if len(rule.Name) == 0 {
+		return fmt.Errorf("name is not set")
+	}
+	if len(rule.MinSeverity) > 0 {
+		if _, found := severityRanks[strings.ToUpper(rule.MinSeverity)]; !found {
+			return fmt.Errorf("unknown severity %s", rule.MinSeverity)
+		}
+	}
+	if len(rule.Pattern) > 0 {
+		if rule.pattern, err = regexp.Compile(rule.Pattern); err != nil {
+			return err
+		}
+	}
+	if len(rule.PathPattern) > 0 {
+		if rule.pathPattern, err = regexp.Compile(rule.PathPattern); err != nil {
+			return err
+		}
+	}
+	if rule.pattern == nil && rule.pathPattern == nil && len(rule.MinSeverity) == 0 {
+		return fmt.Errorf("rule has no conditions")
+	}
+	return nil
+}
+
+//MatchesEntry checks severity, entity and text of the entry
+func (rule AlertRule) MatchesEntry(entry LogEntry) bool {
+	if rule.pathPattern != nil {
+		return false
+	}
+	if len(rule.Entity) > 0 && !strings.EqualFold(rule.Entity, entry.EntityName) {
+		return false
+	}
+	if len(rule.MinSeverity) > 0 {
+		rank, known := severityRanks[strings.ToUpper(strings.TrimSpace(entry.Severity))]
+		if !known || rank < severityRanks[strings.ToUpper(rule.MinSeverity)] {
+			return false
+		}
+	}
+	return rule.pattern == nil || rule.pattern.MatchString(entry.Text)
+}
+
+//MatchesFile checks the name of the file or folder added to the analyzed folder
+func (rule AlertRule) MatchesFile(path string) bool {
+	return rule.pathPattern != nil && rule.pathPattern.MatchString(filepath.Base(path))
+}
+
+//raiseEntryAlerts records alerts for the entry added during live update and notifies UI with "AlertRaised" event
+func (a *Analyzer) raiseEntryAlerts(entry LogEntry) {
+	for _, rule := range alertRules {
+		if rule.MatchesEntry(entry) {
+			a.raiseAlert(Alert{Rule: rule.Name, Time: entry.Time, Severity: entry.Severity, Text: firstLine(entry.Text)})
+		}
+	}
+}
+
+//raiseFileAlerts records alerts for the file found during live update
+func (a *Analyzer) raiseFileAlerts(path string) {
+	created := GetFileModTime(path)
+	if created.IsZero() {
+		created = time.Now()
+	}
+	for _, rule := range alertRules {
+		if rule.MatchesFile(path) {
+			a.raiseAlert(Alert{Rule: rule.Name, Time: created.UTC(), Text: filepath.Base(path)})
+		}
+	}
+}
+
+func (a *Analyzer) raiseAlert(alert Alert) {
+	writeSyncer.Lock()
+	a.Alerts = append(a.Alerts, alert)
+	writeSyncer.Unlock()
+	log.Printf("Alert \"%s\": %s", alert.Rule, alert.Text)
+	a.emitEvent("AlertRaised", alert.Rule, alert.Text)
+}
+
+//GetAlerts returns a copy of alerts raised during live update, the latest goes first
+func (a *Analyzer) GetAlerts() Alerts {
+	writeSyncer.Lock()
+	defer writeSyncer.Unlock()
+	alerts := make(Alerts, 0, len(a.Alerts))
+	for i := len(a.Alerts) - 1; i >= 0; i-- {
+		alerts = append(alerts, a.Alerts[i])
+	}
+	return alerts
+}
+
+type Alerts []Alert
+
+//DisplayTime returns the time of the alert in the time zone chosen for displaying
+func (alert Alert) DisplayTime() time.Time {
+	return alert.Time.In(getDisplayLocation())
+}
+
+//ConvertToHTML renders the list of alerts based on Alerts.gohtml template
+func (alerts Alerts) ConvertToHTML() string {
+	if len(alerts) == 0 {
+		return ""
+	}
+	var tpl bytes.Buffer
+	t := template.Must(template.New("Alerts.gohtml").
+		ParseFS(tmplFS, "Alerts.gohtml"))
+	err := t.Execute(&tpl, alerts)
+	if err != nil {
+		log.Printf("Template Alerts.gohtml execution failed. Error: %s", err.Error())
+	}
+	return tpl.String()
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
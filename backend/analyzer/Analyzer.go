@@ -5,7 +5,6 @@ import (
 	"crypto/sha1"
 	"embed"
 	"fmt"
-	"github.com/nxadm/tail"
 	"log"
 	"os"
 	"path/filepath"
@@ -24,18 +23,26 @@ var writeSyncer = sync.Mutex{}
 var tmplFS embed.FS
 
 type Analyzer struct {
-	Context               *context.Context
-	FolderToWorkWith      string
-	IsFolderTemp          bool
-	fileWatchers          []*tail.Tail
-	LastModifiedFileTime  time.Time
-	DynamicEntities       DynamicEntities
-	StaticEntities        []StaticEntity
-	Filters               Filters
-	OtherFiles            OtherFiles
-	AggregatedLogs        Logs
-	AggregatedThreadDumps AggregatedThreadDumps
-	AggregatedStaticInfo  AggregatedStaticInfo
+	Context                   *context.Context
+	FolderToWorkWith          string
+	AdditionalFolders         []string // AdditionalFolders are analyzed together with FolderToWorkWith, for example indexing diagnostics from the system directory of the IDE. They are not watched during live update.
+	IsFolderTemp              bool
+	liveUpdate                *liveUpdate
+	LastModifiedFileTime      time.Time
+	SourceTimeZone            *time.Location // SourceTimeZone is the time zone logs of the bundle were written in. Nil means it was not detected yet, UTC is used.
+	DynamicEntities           DynamicEntities
+	StaticEntities            []StaticEntity
+	Filters                   Filters
+	OtherFiles                OtherFiles
+	AggregatedLogs            Logs
+	AggregatedThreadDumps     AggregatedThreadDumps
+	AggregatedStaticInfo      AggregatedStaticInfo
+	AggregatedGCEvents        GCEvents
+	AggregatedCrashReports    CrashReports
+	AggregatedPluginErrors    PluginErrors
+	AggregatedIndexingHistory IndexingHistory
+	Alerts                    []Alert  // Alerts are raised by alert rules during live update
+	extractedArchives         []string // extractedArchives are temp folders with content of nested archives, removed on Clear
 }
 type StaticEntity struct {
 	Name                string
@@ -79,16 +86,36 @@ func (a *Analyzer) AddStaticEntity(entity StaticEntity) {
 	a.StaticEntities = append(a.StaticEntities, entity)
 }
 
+//GetDynamicEntityNames returns sorted unique names of known dynamic entities
+func (a *Analyzer) GetDynamicEntityNames() (names []string) {
+	for _, entity := range a.DynamicEntities {
+		if SliceContains(names, entity.Name) == -1 {
+			names = append(names, entity.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 //AddDynamicEntity adds new dynamic Entity to the list of known Entities. Should be Called within the application start.
 func (a *Analyzer) AddDynamicEntity(entity DynamicEntity) {
+	// the map is created here, so goroutines parsing files in parallel only write into it under lock
+	entity.entityInstances = make(map[string]DynamicEntityProperties)
 	a.DynamicEntities = append(a.DynamicEntities, entity)
 }
 
 //ParseLogDirectory analyzes provided path for known log elements
 func (a *Analyzer) ParseLogDirectory(path string) {
+	a.parseLogDirectory(path, 0)
+	a.enforceMaxLogEntries()
+}
+
+//parseLogDirectory analyzes the folder. depth is the number of archives the folder is extracted from
+func (a *Analyzer) parseLogDirectory(path string, depth int) {
 	log.Printf("Parsing log directory %s", path)
 	var wg sync.WaitGroup
 	var collectedFiles []string
+	var archives []string
 	visit := func(path string, file os.DirEntry, err error) error {
 		wg.Add(1)
 		go func() {
@@ -101,6 +128,9 @@ func (a *Analyzer) ParseLogDirectory(path string) {
 			} else {
 				if !file.IsDir() && !IsHiddenFile(filepath.Base(path)) {
 					a.OtherFiles.Append(path)
+					if strings.EqualFold(filepath.Ext(path), ".zip") {
+						archives = append(archives, path)
+					}
 				}
 			}
 			writeSyncer.Unlock()
@@ -110,6 +140,16 @@ func (a *Analyzer) ParseLogDirectory(path string) {
 	_ = filepath.WalkDir(path, visit)
 	wg.Wait()
 	a.OtherFiles = a.OtherFiles.FilterAnalyzedDirectories(collectedFiles)
+	if settings := GetParsingSettings(); settings.FollowNestedArchives && depth < maxNestedArchivesDepth {
+		sort.Strings(archives)
+		for _, archive := range archives {
+			if settings.isTooBigToParse(archive) {
+				log.Printf("Nested archive %s is bigger than %d MB, skipping it", archive, settings.MaxParsedFileSizeMB)
+				continue
+			}
+			a.parseNestedArchive(archive, depth)
+		}
+	}
 }
 
 func (a *Analyzer) GetLastModifiedFile() time.Time {
@@ -143,16 +183,52 @@ func (a *Analyzer) GetLogs() *Logs {
 	return nil
 }
 
+//GetVisibleLogs returns a copy of logs checked in filters. Live update may change the logs while the copy is used.
+func (a *Analyzer) GetVisibleLogs() Logs {
+	writeSyncer.Lock()
+	defer writeSyncer.Unlock()
+	visible := Logs{}
+	for _, entry := range a.AggregatedLogs {
+		if entry.Visible {
+			visible = append(visible, entry)
+		}
+	}
+	return visible
+}
+
+//SetFilters sets the Checked values of filter entries with given IDs and applies filters to the logs
+func (a *Analyzer) SetFilters(f map[string]bool) {
+	writeSyncer.Lock()
+	defer writeSyncer.Unlock()
+	for _, entries := range a.Filters {
+		for i, entry := range entries.Entries {
+			if value, found := f[entry.ID]; found {
+				entries.Entries[i].Checked = value
+			}
+		}
+	}
+	a.AggregatedLogs.ApplyFilters(&a.Filters)
+}
+
 func (a *Analyzer) GetStaticInfo() *AggregatedStaticInfo {
-	if !(len(a.AggregatedStaticInfo) == 0) {
-		return &a.AggregatedStaticInfo
+	writeSyncer.Lock()
+	defer writeSyncer.Unlock()
+	if len(a.AggregatedStaticInfo) == 0 {
+		a.AggregatedStaticInfo = aggregateStaticInfo(a.StaticEntities)
+		if pluginCatalog != nil {
+			a.AggregatedStaticInfo.annotatePlugins(pluginCatalog)
+		}
 	}
-	a.AggregatedStaticInfo = aggregateStaticInfo(a.StaticEntities)
-	return a.GetStaticInfo()
+	return &a.AggregatedStaticInfo
 }
+
+//GetOtherFiles returns a copy of files that are not parsed. Live update may add files while the copy is used.
 func (a *Analyzer) GetOtherFiles() *OtherFiles {
+	writeSyncer.Lock()
+	defer writeSyncer.Unlock()
 	if !a.AggregatedLogs.IsEmpty() {
-		return &a.OtherFiles
+		otherFiles := append(OtherFiles{}, a.OtherFiles...)
+		return &otherFiles
 	}
 	return nil
 }
@@ -165,20 +241,56 @@ func (a *Analyzer) GetThreadDump(threadDumpsFolder string) *ThreadDump {
 	}
 	a.AggregatedThreadDumps[threadDumpsFolder] = make(ThreadDump)
 	a.AggregatedThreadDumps[threadDumpsFolder] = analyzeThreadDumpsFolder(a.FolderToWorkWith, threadDumpsFolder)
+	for _, folder := range a.AdditionalFolders {
+		for path, file := range analyzeThreadDumpsFolder(folder, threadDumpsFolder) {
+			a.AggregatedThreadDumps[threadDumpsFolder][path] = file
+		}
+	}
 	return a.GetThreadDump(threadDumpsFolder)
 }
+//GetFilters returns a copy of filters. Live update may add filter entries while the copy is used.
 func (a *Analyzer) GetFilters() *Filters {
-	if !a.Filters.IsEmpty() {
-		return &a.Filters
+	writeSyncer.Lock()
+	defer writeSyncer.Unlock()
+	if a.Filters.IsEmpty() {
+		return nil
 	}
-	return nil
+	filters := make(Filters)
+	for name, group := range a.Filters {
+		group.Entries = append(FilterEntries{}, group.Entries...)
+		filters[name] = group
+	}
+	return &filters
+}
+
+//getInstancePaths returns sorted paths of the instances of the entity. Live update adds instances, so they are read under lock.
+func (a *Analyzer) getInstancePaths(entityName string) (paths []string) {
+	writeSyncer.Lock()
+	defer writeSyncer.Unlock()
+	for _, entity := range a.DynamicEntities {
+		if entity.Name == entityName {
+			for path := range entity.entityInstances {
+				paths = append(paths, path)
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths
 }
 
 func (a *Analyzer) CollectStaticInfoFromStaticEntities(path string) (analyzed bool) {
 	analyzed = false
+	settings := GetParsingSettings()
 	for i, entity := range a.StaticEntities {
 		if entity.CheckPath(path) == true {
-			a.StaticEntities[i].CollectedInfo = entity.ConvertToStaticInfo(path)
+			if settings.isTooBigToParse(path) {
+				log.Printf("File %s is bigger than %d MB, static info is not collected from it", path, settings.MaxParsedFileSizeMB)
+				return false
+			}
+			info := entity.ConvertToStaticInfo(path)
+			writeSyncer.Lock()
+			a.StaticEntities[i].CollectedInfo.merge(info)
+			writeSyncer.Unlock()
 			analyzed = true
 		}
 	}
@@ -188,6 +300,7 @@ func (a *Analyzer) CollectStaticInfoFromStaticEntities(path string) (analyzed bo
 // CollectLogsFromDynamicEntities Checks if path fulfil the Entity requirements and Adds all the Entity's logEntries to the aggregated logs
 func (a *Analyzer) CollectLogsFromDynamicEntities(path string) (analyzed bool) {
 	analyzed = false
+	settings := GetParsingSettings()
 	for i, entity := range a.DynamicEntities {
 		if entity.CheckIgnoredPath != nil {
 			if entity.CheckIgnoredPath(path) == true {
@@ -195,6 +308,10 @@ func (a *Analyzer) CollectLogsFromDynamicEntities(path string) (analyzed bool) {
 			}
 		}
 		if entity.CheckPath(path) == true {
+			if settings.isTooBigToParse(path) {
+				log.Printf("File %s is bigger than %d MB, it is not parsed", path, settings.MaxParsedFileSizeMB)
+				return false
+			}
 			logEntries := entity.ConvertPathToLogs(path)
 			if logEntries == nil {
 				log.Printf("Entity \"%s\" returned nothing for %s. Adding file to other files", entity.Name, path)
@@ -204,8 +321,12 @@ func (a *Analyzer) CollectLogsFromDynamicEntities(path string) (analyzed bool) {
 						return true
 					}
 				}
+				for j := range logEntries {
+					a.normalizeEntryTime(&logEntries[j])
+				}
+				visible := entity.DefaultVisibility(path) && settings.isVisibleByDefault(entity.Name, path)
 				writeSyncer.Lock()
-				a.DynamicEntities[i].addDynamicEntityInstance(path, entity.DefaultVisibility(path))
+				a.DynamicEntities[i].addDynamicEntityInstance(path, visible)
 				a.AggregatedLogs.AppendSeveral(a.DynamicEntities[i].Name, a.DynamicEntities[i].entityInstances[path], logEntries)
 				writeSyncer.Unlock()
 				analyzed = true
@@ -232,18 +353,32 @@ func (a *Analyzer) InitFilter() *Filters {
 }
 
 func (a *Analyzer) Clear() {
+	a.stopLiveUpdate()
+	writeSyncer.Lock()
 	a.AggregatedLogs = Logs{}
 	a.Filters = Filters{}
 	a.OtherFiles = OtherFiles{}
 	a.AggregatedStaticInfo = AggregatedStaticInfo{}
 	a.AggregatedThreadDumps = AggregatedThreadDumps{}
+	a.AggregatedGCEvents = nil
+	a.AggregatedCrashReports = nil
+	a.AggregatedPluginErrors = nil
+	a.AggregatedIndexingHistory = nil
+	gcLogsCache.clear()
+	crashLogsCache.clear()
+	indexingDiagnosticsCache.clear()
+	a.Alerts = nil
 	a.LastModifiedFileTime = time.Time{}
+	sourceTimeZoneMutex.Lock()
+	a.SourceTimeZone = nil
+	sourceTimeZoneMutex.Unlock()
 	for i, _ := range a.StaticEntities {
 		a.StaticEntities[i].CollectedInfo = StaticInfo{}
 	}
 	for i, _ := range a.DynamicEntities {
 		a.DynamicEntities[i].entityInstances = make(map[string]DynamicEntityProperties)
 	}
+	writeSyncer.Unlock()
 	if a.IsFolderTemp {
 		err := os.RemoveAll(a.FolderToWorkWith)
 		if err != nil {
@@ -253,12 +388,13 @@ func (a *Analyzer) Clear() {
 		}
 	}
 	a.IsFolderTemp = false
-	for _, watcher := range a.fileWatchers {
-		if watcher != nil {
-			watcher.Stop()
+	a.AdditionalFolders = nil
+	for _, folder := range a.extractedArchives {
+		if err := os.RemoveAll(folder); err != nil {
+			log.Printf("Removing folder '%s' failed. Error: %s", folder, err)
 		}
 	}
-	a.fileWatchers = nil
+	a.extractedArchives = nil
 }
 
 func (a *Analyzer) GetThreadDumps(dir string) Logs {
@@ -0,0 +1,55 @@
+package analyzer
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//ExtractZip extracts the archive to dest keeping modification times of files. Files with paths outside dest are rejected.
+func ExtractZip(src string, dest string) error {
+	r, err := zip.OpenReader(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if err := extractZipFile(f, dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractZipFile(f *zip.File, dest string) error {
+	path := filepath.Join(dest, f.Name)
+	// Check for ZipSlip (Directory traversal)
+	if !strings.HasPrefix(path, filepath.Clean(dest)+string(os.PathSeparator)) {
+		return fmt.Errorf("illegal file path: %s", path)
+	}
+	if f.FileInfo().IsDir() {
+		return os.MkdirAll(path, 0755)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Printf("Failed to make dir %s. Error: %s", filepath.Dir(path), err)
+	}
+	archiveFile, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer archiveFile.Close()
+	destfile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(destfile, archiveFile)
+	if closeErr := destfile.Close(); err == nil {
+		err = closeErr
+	}
+	_ = os.Chtimes(path, f.Modified, f.Modified)
+	return err
+}
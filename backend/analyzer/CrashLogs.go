@@ -0,0 +1,234 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var (
+	crashTimeRegex         = regexp.MustCompile(`(?i)^Time:\s*(?P<Date>\w{3}\s+\w{3}\s+\d+\s+\d{2}:\d{2}:\d{2}\s+\d{4})\s*(?P<Zone>.*?)\s*(elapsed time.*)?$`)
+	crashOffsetRegex       = regexp.MustCompile(`^(UTC|GMT)?(?P<Sign>[+-])(?P<Hours>\d{2}):?(?P<Minutes>\d{2})$`)
+	crashLogNameRegex      = regexp.MustCompile(`^(hs_err_pid\d+|java_error_in_.*|jbr_err_pid\d+)\.log$`)
+	crashSignalRegex       = regexp.MustCompile(`^#\s+(?P<Signal>(SIG[A-Z]+|EXCEPTION_[A-Z_]+|Internal Error)\b.*)`)
+	crashJREVersionRegex   = regexp.MustCompile(`^#\s+JRE version:\s*(?P<Version>.*)`)
+	crashJavaVMRegex       = regexp.MustCompile(`^#\s+Java VM:\s*(?P<VM>.*)`)
+	crashCurrentThread     = regexp.MustCompile(`^Current thread \([^)]*\):\s*(?P<Thread>.*)`)
+	crashCommandLineRegex  = regexp.MustCompile(`^Command Line:\s*(?P<CommandLine>.*)`)
+	crashMemoryRegex       = regexp.MustCompile(`^Memory:\s*(?P<Memory>.*)`)
+	crashOutOfMemoryRegex  = regexp.MustCompile(`^#\s+(?P<Reason>(There is insufficient memory|Out of Memory Error).*)`)
+	crashPidFromNameRegex  = regexp.MustCompile(`(pid|_)(?P<Pid>\d+)\.log$`)
+	crashProblematicHeader = "# Problematic frame:"
+)
+
+//CrashReport is a summary of JVM fatal error log (hs_err_pid*.log, java_error_in_*.log, jbr_err_pid*.log)
+type CrashReport struct {
+	ID               string
+	Path             string
+	FileName         string
+	Pid              string
+	Time             time.Time
+	Signal           string // Signal or exception code with pc, pid and tid, for example "SIGSEGV (0xb) at pc=0x00007f..., pid=1234, tid=5678"
+	ProblematicFrame string
+	CurrentThread    string
+	JREVersion       string
+	JavaVM           string
+	Memory           string
+	CommandLine      string
+	HasTimeZone      bool // HasTimeZone is true if "Time:" line has UTC or numeric offset, such crash time does not depend on the source time zone
+}
+
+//crashLogsCache keeps summaries of fatal error logs, they are parsed once for the logs and for the crashes tab
+var crashLogsCache parseCache[CrashReport]
+
+//CrashReports is a list of JVM crashes sorted by time
+type CrashReports []CrashReport
+
+//IsJVMCrashLog matches fatal error logs written by JVM on crash
+func IsJVMCrashLog(path string) bool {
+	return crashLogNameRegex.MatchString(filepath.Base(path))
+}
+
+//ParseCrashLog collects the summary of the crash from the header and the sections of the fatal error log.
+//The file is parsed once, the next calls return cached summary until the file changes.
+func ParseCrashLog(path string) CrashReport {
+	return crashLogsCache.get(path, parseCrashLog)
+}
+
+func parseCrashLog(path string) (c CrashReport) {
+	c.ID = getHash(path)
+	c.Path = path
+	c.FileName = filepath.Base(path)
+	if match := crashPidFromNameRegex.FindStringSubmatch(c.FileName); match != nil {
+		c.Pid = match[crashPidFromNameRegex.SubexpIndex("Pid")]
+	}
+	reader, err := os.Open(path)
+	if err != nil {
+		log.Printf("ParseCrashLog failed. path: %s, error: %s", path, err)
+		return c
+	}
+	defer reader.Close()
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	problematicFrameIsNext := false
+	firstMatch := func(r *regexp.Regexp, s string, group string) string {
+		if match := r.FindStringSubmatch(s); match != nil {
+			return strings.TrimSpace(match[r.SubexpIndex(group)])
+		}
+		return ""
+	}
+	for scanner.Scan() {
+		s := scanner.Text()
+		if problematicFrameIsNext {
+			c.ProblematicFrame = strings.TrimSpace(strings.TrimPrefix(s, "#"))
+			problematicFrameIsNext = false
+		}
+		if strings.HasPrefix(s, crashProblematicHeader) {
+			problematicFrameIsNext = true
+		}
+		if v := firstMatch(crashSignalRegex, s, "Signal"); len(v) > 0 && len(c.Signal) == 0 {
+			c.Signal = v
+		}
+		if v := firstMatch(crashOutOfMemoryRegex, s, "Reason"); len(v) > 0 && len(c.Signal) == 0 {
+			c.Signal = v
+		}
+		if v := firstMatch(crashJREVersionRegex, s, "Version"); len(v) > 0 {
+			c.JREVersion = v
+		}
+		if v := firstMatch(crashJavaVMRegex, s, "VM"); len(v) > 0 {
+			c.JavaVM = v
+		}
+		if v := firstMatch(crashCurrentThread, s, "Thread"); len(v) > 0 && len(c.CurrentThread) == 0 {
+			c.CurrentThread = v
+		}
+		if v := firstMatch(crashCommandLineRegex, s, "CommandLine"); len(v) > 0 {
+			c.CommandLine = v
+		}
+		if v := firstMatch(crashMemoryRegex, s, "Memory"); len(v) > 0 && len(c.Memory) == 0 {
+			c.Memory = v
+		}
+		if v := firstMatch(crashTimeRegex, s, "Date"); len(v) > 0 {
+			c.Time, c.HasTimeZone, err = parseCrashTime(strings.Join(strings.Fields(v), " "), firstMatch(crashTimeRegex, s, "Zone"))
+			if err != nil {
+				log.Printf("Could not parse crash time '%s' in %s: %s", v, path, err)
+			}
+		}
+	}
+	if c.Time.IsZero() {
+		modTime := GetFileModTime(path)
+		c.Time = time.Date(modTime.Year(), modTime.Month(), modTime.Day(), modTime.Hour(), modTime.Minute(), modTime.Second(), 0, time.UTC)
+	}
+	return c
+}
+
+//parseCrashTime parses the date of "Time:" line. UTC, numeric offsets and zones known to time package (EST, CET) are applied.
+//Other zone names (CEST, "Central European Summer Time") are ambiguous, such time is kept as UTC wall clock the same way as for other logs
+//and is moved to the source time zone.
+func parseCrashTime(date string, zone string) (t time.Time, hasTimeZone bool, err error) {
+	t, err = time.Parse("Mon Jan _2 15:04:05 2006", date)
+	if err != nil {
+		return t, false, err
+	}
+	if zone == "UTC" || zone == "GMT" || zone == "Z" {
+		return t, true, nil
+	}
+	if match := crashOffsetRegex.FindStringSubmatch(zone); match != nil {
+		hours, _ := strconv.Atoi(match[crashOffsetRegex.SubexpIndex("Hours")])
+		minutes, _ := strconv.Atoi(match[crashOffsetRegex.SubexpIndex("Minutes")])
+		offset := hours*3600 + minutes*60
+		if match[crashOffsetRegex.SubexpIndex("Sign")] == "-" {
+			offset = -offset
+		}
+		return t.Add(-time.Duration(offset) * time.Second), true, nil
+	}
+	if len(zone) > 0 && zone != "Local" && !strings.Contains(zone, " ") {
+		if loc, err := time.LoadLocation(zone); err == nil {
+			return changeTimeZone(t, time.UTC, loc), true, nil
+		}
+	}
+	return t, false, nil
+}
+
+//ConvertToLogs represents the crash as a single CRASH log entry
+func (c CrashReport) ConvertToLogs() Logs {
+	text := "JVM crash"
+	if len(c.Signal) > 0 {
+		text = text + ": " + c.Signal
+	}
+	if len(c.ProblematicFrame) > 0 {
+		text = text + "\nProblematic frame: " + c.ProblematicFrame
+	}
+	if len(c.CurrentThread) > 0 {
+		text = text + "\nCurrent thread: " + c.CurrentThread
+	}
+	text = text + "\nReport: " + c.FileName
+	return Logs{{
+		Severity:    "CRASH",
+		Time:        c.Time,
+		Text:        text,
+		HasTimeZone: c.HasTimeZone,
+	}}
+}
+
+//DisplayTime returns the time of the crash in the time zone chosen for displaying
+func (c CrashReport) DisplayTime() time.Time {
+	return c.Time.In(getDisplayLocation())
+}
+
+//GetCrashReports returns all JVM crashes of the bundle. Analyzes them if it was not done already.
+func (a *Analyzer) GetCrashReports() CrashReports {
+	writeSyncer.Lock()
+	cached := a.AggregatedCrashReports
+	writeSyncer.Unlock()
+	if cached != nil {
+		return cached
+	}
+	reports := CrashReports{}
+	for _, path := range a.getInstancePaths("JVM Crash") {
+		report := ParseCrashLog(path)
+		if !report.HasTimeZone {
+			report.Time = a.normalizeTime(report.Time)
+		}
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Time.Before(reports[j].Time) })
+	writeSyncer.Lock()
+	a.AggregatedCrashReports = reports
+	writeSyncer.Unlock()
+	return reports
+}
+
+//GetContent returns the full text of the fatal error log
+func (reports CrashReports) GetContent(id string) string {
+	for _, report := range reports {
+		if report.ID == id {
+			content, _ := ioutil.ReadFile(report.Path)
+			return string(content)
+		}
+	}
+	return ""
+}
+
+//ConvertToHTML renders crash summaries based on CrashReports.gohtml template
+func (reports CrashReports) ConvertToHTML() string {
+	if len(reports) == 0 {
+		return ""
+	}
+	var tpl bytes.Buffer
+	t := template.Must(template.New("CrashReports.gohtml").
+		ParseFS(tmplFS, "CrashReports.gohtml"))
+	err := t.Execute(&tpl, reports)
+	if err != nil {
+		log.Printf("Template CrashReports.gohtml execution failed. Error: %s", err.Error())
+	}
+	return tpl.String()
+}
@@ -0,0 +1,174 @@
+package analyzer
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//SourcedValue is a value of the environment fact together with the source it was taken from, for example "troubleshooting.txt"
+type SourcedValue struct {
+	Source string
+	Value  string
+}
+
+//EnvironmentFact is a single fact (build, JRE, OS...) as reported by every source that knows it
+type EnvironmentFact struct {
+	Name     string
+	Values   []SourcedValue // Values are ordered by source priority, the first one is displayed as the value of the fact
+	Conflict bool           // Conflict is true if sources report different values
+}
+
+//EnvironmentSummary is the environment combined from all static info sources
+type EnvironmentSummary []EnvironmentFact
+
+//staticInfoSourcePriority defines which source is trusted more when sources disagree. Unknown sources go last in alphabetical order.
+var staticInfoSourcePriority = []string{"troubleshooting.txt", "product-info.json", "idea.log", "vmoptions"}
+
+var (
+	buildNumberRegex = regexp.MustCompile(`\d{3}\.\d+(\.\d+)*`)
+	parenthesesRegex = regexp.MustCompile(`\([^)]*\)`)
+	digitsRegex      = regexp.MustCompile(`\d+`)
+)
+
+type environmentFactDefinition struct {
+	name      string
+	get       func(StaticInfo) string
+	normalize func(string) string // normalize converts the value to the form in which values from different sources could be compared
+}
+
+var environmentFacts = []environmentFactDefinition{
+	{name: "IDE", get: func(s StaticInfo) string { return s.IDE }, normalize: normalizeIDE},
+	{name: "Build", get: func(s StaticInfo) string { return s.Build }, normalize: normalizeBuild},
+	{name: "JRE", get: func(s StaticInfo) string { return s.JRE }, normalize: firstField},
+	{name: "Runtime", get: func(s StaticInfo) string { return s.Runtime }, normalize: firstField},
+	{name: "OS", get: func(s StaticInfo) string { return s.OS }, normalize: normalizeOS},
+	{name: "Cores", get: func(s StaticInfo) string { return s.Cores }, normalize: firstNumber},
+	{name: "Heap", get: func(s StaticInfo) string { return s.Heap }, normalize: normalizeMemory},
+	{name: "Xmx", get: func(s StaticInfo) string { return s.JVMSettings.Xmx }, normalize: normalizeMemory},
+	{name: "Time zone", get: func(s StaticInfo) string { return s.TimeZone }, normalize: normalizeText},
+}
+
+//Sources returns names of the sources ordered by staticInfoSourcePriority
+func (a AggregatedStaticInfo) Sources() []string {
+	priority := func(source string) int {
+		for i, s := range staticInfoSourcePriority {
+			if s == source {
+				return i
+			}
+		}
+		return len(staticInfoSourcePriority)
+	}
+	sources := make([]string, 0, len(a))
+	for source := range a {
+		sources = append(sources, source)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		if priority(sources[i]) != priority(sources[j]) {
+			return priority(sources[i]) < priority(sources[j])
+		}
+		return sources[i] < sources[j]
+	})
+	return sources
+}
+
+//Merge combines facts from all the sources. Every value keeps its source, facts with different values are marked as conflicting.
+func (a AggregatedStaticInfo) Merge() (summary EnvironmentSummary) {
+	sources := a.Sources()
+	for _, definition := range environmentFacts {
+		fact := EnvironmentFact{Name: definition.name}
+		normalized := ""
+		for _, source := range sources {
+			value := strings.TrimSpace(definition.get(a[source]))
+			if len(value) == 0 {
+				continue
+			}
+			fact.Values = append(fact.Values, SourcedValue{Source: source, Value: value})
+			if n := definition.normalize(value); len(normalized) == 0 {
+				normalized = n
+			} else if n != normalized {
+				fact.Conflict = true
+			}
+		}
+		if len(fact.Values) > 0 {
+			summary = append(summary, fact)
+		}
+	}
+	return summary
+}
+
+//Value returns the value of the most trusted source
+func (f EnvironmentFact) Value() string {
+	if len(f.Values) == 0 {
+		return ""
+	}
+	return f.Values[0].Value
+}
+
+//HasConflicts returns true if at least one fact is reported differently by the sources
+func (summary EnvironmentSummary) HasConflicts() bool {
+	for _, fact := range summary {
+		if fact.Conflict {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeText(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+//normalizeBuild extracts build number, so "#IU-223.8214.52, built on December 20, 2022" and "IU-223.8214.52" are equal
+func normalizeBuild(s string) string {
+	if build := buildNumberRegex.FindString(s); len(build) > 0 {
+		return build
+	}
+	return normalizeText(s)
+}
+
+//withoutParentheses ignores details like "(Ultimate Edition)"
+func withoutParentheses(s string) string {
+	return normalizeText(parenthesesRegex.ReplaceAllString(s, ""))
+}
+
+//normalizeIDE compares product names only, so "IntelliJ IDEA" and "IntelliJ IDEA 2022.3.1 (Ultimate Edition)" are equal. Versions are compared by Build.
+func normalizeIDE(s string) string {
+	var words []string
+	for _, word := range strings.Fields(withoutParentheses(s)) {
+		if !digitsRegex.MatchString(word) {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+//normalizeOS ignores details in parentheses, so "Windows 10 (10.0, amd64)" and "Windows 10 10.0" are compared by name
+func normalizeOS(s string) string {
+	fields := strings.Fields(withoutParentheses(s))
+	if len(fields) > 2 {
+		fields = fields[:2]
+	}
+	return strings.Join(fields, " ")
+}
+
+func firstField(s string) string {
+	if fields := strings.Fields(s); len(fields) > 0 {
+		return strings.ToLower(fields[0])
+	}
+	return ""
+}
+
+func firstNumber(s string) string {
+	return digitsRegex.FindString(s)
+}
+
+//normalizeMemory converts memory sizes like "2048M", "2048 MB" or "2g" to megabytes
+func normalizeMemory(s string) string {
+	size := strings.TrimSuffix(strings.ToLower(strings.ReplaceAll(s, " ", "")), "b")
+	if mb, err := parseMemorySizeMB(size); err == nil {
+		return strconv.Itoa(mb)
+	}
+	return normalizeText(s)
+}
@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"strconv"
+)
+
+//GetFilterStates returns Checked values of filters keyed by paths of entity instances relative to the analyzed folders.
+//Unlike IDs of FilterEntry, the keys do not change when the same logs are extracted to another folder.
+func (a *Analyzer) GetFilterStates() map[string]bool {
+	writeSyncer.Lock()
+	defer writeSyncer.Unlock()
+	checked := make(map[string]bool)
+	for _, entries := range a.Filters {
+		for _, entry := range entries.Entries {
+			checked[entry.ID] = entry.Checked
+		}
+	}
+	states := make(map[string]bool)
+	for _, entity := range a.DynamicEntities {
+		for path, instance := range entity.entityInstances {
+			if value, found := checked[instance.Hash]; found {
+				states[a.filterStateKey(path)] = value
+			}
+		}
+	}
+	return states
+}
+
+//RestoreFilterStates sets Checked values of filters saved by GetFilterStates. Instances not mentioned in states keep their default visibility.
+func (a *Analyzer) RestoreFilterStates(states map[string]bool) {
+	if len(states) == 0 {
+		return
+	}
+	filters := make(map[string]bool)
+	writeSyncer.Lock()
+	for _, entity := range a.DynamicEntities {
+		for path, instance := range entity.entityInstances {
+			if value, found := states[a.filterStateKey(path)]; found {
+				filters[instance.Hash] = value
+			}
+		}
+	}
+	writeSyncer.Unlock()
+	a.SetFilters(filters)
+}
+
+//filterStateKey is the path relative to the analyzed folder containing it. Paths inside AdditionalFolders are prefixed with the index of the folder.
+func (a *Analyzer) filterStateKey(path string) string {
+	roots := append([]string{a.FolderToWorkWith}, a.AdditionalFolders...)
+	for i, root := range roots {
+		if rel, err := filepath.Rel(root, path); err == nil && rel != ".." && !filepath.IsAbs(rel) && !startsWithParentDir(rel) {
+			if i == 0 {
+				return filepath.ToSlash(rel)
+			}
+			return strconv.Itoa(i) + ":" + filepath.ToSlash(rel)
+		}
+	}
+	return path
+}
+
+func startsWithParentDir(rel string) bool {
+	return len(rel) >= 3 && rel[:2] == ".." && rel[2] == filepath.Separator
+}
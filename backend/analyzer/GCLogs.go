@@ -0,0 +1,272 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//LongGCPauseThreshold is the duration of GC pause that is considered as a possible reason of a freeze
+var LongGCPauseThreshold = 500 * time.Millisecond
+
+//gcLogsCache keeps pauses of every GC log, they are parsed once for the logs and for the GC tab
+var gcLogsCache parseCache[GCEvents]
+
+var (
+	gcDecorationRegex = regexp.MustCompile(`^\[([^\]]*)\]`)
+	gcDateRegex       = regexp.MustCompile(`^(?P<Date>\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?)(?P<Offset>[+-]\d{4})?`)
+	gcUptimeRegex     = regexp.MustCompile(`^(\d+(\.\d+)?)(s|ms)$`)
+	gcPauseRegex      = regexp.MustCompile(`GC\((?P<Id>\d+)\)\s+(?P<Type>Pause.*?)\s+(?P<Before>\d+)(?P<BeforeUnit>[KMG])->(?P<After>\d+)(?P<AfterUnit>[KMG])\((?P<Total>\d+)(?P<TotalUnit>[KMG])\)\s+(?P<Duration>[\d.]+)ms`)
+)
+
+//GCEvent is a single GC pause parsed from the JVM unified logging (-Xlog:gc*)
+type GCEvent struct {
+	Time         time.Time
+	ID           int
+	Type         string // Type of the pause, for example "Pause Young (Normal) (G1 Evacuation Pause)" or "Pause Full (System.gc())"
+	HeapBeforeMB float64
+	HeapAfterMB  float64
+	HeapTotalMB  float64
+	Pause        time.Duration
+	Text         string
+	HasTimeZone  bool // HasTimeZone is true if "time" decoration has the offset, such events do not depend on the source time zone
+}
+
+//GCEvents is a list of GC pauses sorted by time
+type GCEvents []GCEvent
+
+func (e GCEvent) IsFull() bool {
+	return strings.HasPrefix(e.Type, "Pause Full")
+}
+func (e GCEvent) IsLong() bool {
+	return e.Pause >= LongGCPauseThreshold
+}
+
+//ParseGCLog reads GC log file. Timestamps are taken from "time" decoration, if it is absent - from "uptime" decoration and file modification time.
+//The file is parsed once, the next calls return cached pauses until the file changes.
+func ParseGCLog(path string) GCEvents {
+	return gcLogsCache.get(path, parseGCLog)
+}
+
+func parseGCLog(path string) (events GCEvents) {
+	reader, err := os.Open(path)
+	if err != nil {
+		log.Printf("ParseGCLog failed. path: %s, error: %s", path, err)
+		return nil
+	}
+	defer reader.Close()
+	var uptimes []time.Duration
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if event, uptime, ok := parseGCLogString(scanner.Text()); ok {
+			events = append(events, event)
+			uptimes = append(uptimes, uptime)
+		}
+	}
+	// Logs without wall clock decoration are positioned relatively to the last modification of the file
+	if len(events) > 0 && events[len(events)-1].Time.IsZero() {
+		modTime := GetFileModTime(path)
+		lastUptime := uptimes[len(uptimes)-1]
+		start := time.Date(modTime.Year(), modTime.Month(), modTime.Day(), modTime.Hour(), modTime.Minute(), modTime.Second(), modTime.Nanosecond(), time.UTC).Add(-lastUptime)
+		for i := range events {
+			if events[i].Time.IsZero() {
+				events[i].Time = start.Add(uptimes[i])
+			}
+		}
+	}
+	return events
+}
+
+//parseGCLogString parses decorations ([time][uptime][level][tags]) and GC pause message of the line.
+//"time" decoration has the offset (2022-09-01T10:15:30.123+0200), decorations without it are kept as UTC wall clock the same way as for other logs.
+func parseGCLogString(s string) (event GCEvent, uptime time.Duration, ok bool) {
+	message := s
+	for {
+		decoration := gcDecorationRegex.FindStringSubmatch(message)
+		if decoration == nil {
+			break
+		}
+		message = message[len(decoration[0]):]
+		value := strings.TrimSpace(decoration[1])
+		if date := gcDateRegex.FindStringSubmatch(value); date != nil && event.Time.IsZero() {
+			if offset := date[gcDateRegex.SubexpIndex("Offset")]; len(offset) > 0 {
+				event.Time, _ = time.Parse("2006-01-02T15:04:05.999999999-0700", date[gcDateRegex.SubexpIndex("Date")]+offset)
+				event.HasTimeZone = true
+			} else {
+				event.Time, _ = time.Parse("2006-01-02T15:04:05.999999999", date[gcDateRegex.SubexpIndex("Date")])
+			}
+		} else if up := gcUptimeRegex.FindStringSubmatch(value); up != nil {
+			f, _ := strconv.ParseFloat(up[1], 64)
+			if up[3] == "s" {
+				uptime = time.Duration(f * float64(time.Second))
+			} else {
+				uptime = time.Duration(f * float64(time.Millisecond))
+			}
+		}
+	}
+	match := gcPauseRegex.FindStringSubmatch(message)
+	if match == nil {
+		return event, uptime, false
+	}
+	group := func(name string) string {
+		return match[gcPauseRegex.SubexpIndex(name)]
+	}
+	event.ID, _ = strconv.Atoi(group("Id"))
+	event.Type = strings.TrimSpace(group("Type"))
+	event.HeapBeforeMB = toMegabytes(group("Before"), group("BeforeUnit"))
+	event.HeapAfterMB = toMegabytes(group("After"), group("AfterUnit"))
+	event.HeapTotalMB = toMegabytes(group("Total"), group("TotalUnit"))
+	duration, _ := strconv.ParseFloat(group("Duration"), 64)
+	event.Pause = time.Duration(duration * float64(time.Millisecond))
+	event.Text = strings.TrimSpace(message)
+	return event, uptime, true
+}
+
+func toMegabytes(value string, unit string) float64 {
+	v, _ := strconv.ParseFloat(value, 64)
+	switch unit {
+	case "K":
+		return v / 1024
+	case "G":
+		return v * 1024
+	}
+	return v
+}
+
+//ConvertToLogs represents GC pauses as log entries. Long pauses get GC_LONG_PAUSE severity to be highlighted next to freezes.
+func (events GCEvents) ConvertToLogs() (l Logs) {
+	for _, event := range events {
+		severity := "GC_PAUSE"
+		if event.IsLong() {
+			severity = "GC_LONG_PAUSE"
+		}
+		l = append(l, LogEntry{
+			Severity:    severity,
+			Time:        event.Time,
+			Text:        event.Text,
+			HasTimeZone: event.HasTimeZone,
+		})
+	}
+	return l
+}
+
+//GetGCEvents returns GC pauses from all the GC logs of the bundle. Analyzes them if it was not done already.
+func (a *Analyzer) GetGCEvents() GCEvents {
+	writeSyncer.Lock()
+	cached := a.AggregatedGCEvents
+	writeSyncer.Unlock()
+	if cached != nil {
+		return cached
+	}
+	events := GCEvents{}
+	for _, path := range a.getInstancePaths("GC Log") {
+		events = append(events, ParseGCLog(path)...)
+	}
+	for i := range events {
+		if !events[i].HasTimeZone {
+			events[i].Time = a.normalizeTime(events[i].Time)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	writeSyncer.Lock()
+	a.AggregatedGCEvents = events
+	writeSyncer.Unlock()
+	return events
+}
+
+type gcChart struct {
+	Width, Height      int
+	HeapTotalPoints    string
+	HeapAfterPoints    string
+	Bars               []gcChartBar
+	MaxHeapMB          float64
+	MaxPauseMs         float64
+	Start, End         string
+	PausesCount        int
+	FullGCCount        int
+	LongPausesCount    int
+	TotalPause         string
+	LongPauseThreshold string
+}
+type gcChartBar struct {
+	X, Y, Height float64
+	Long, Full   bool
+	Title        string
+}
+
+//ConvertToHTML renders SVG chart of heap occupancy and pause durations based on GCChart.gohtml template
+func (events GCEvents) ConvertToHTML() string {
+	if len(events) == 0 {
+		return ""
+	}
+	chart := gcChart{Width: 600, Height: 160, LongPauseThreshold: LongGCPauseThreshold.String()}
+	start, end := events[0].Time, events[len(events)-1].Time
+	span := end.Sub(start).Seconds()
+	var totalPause time.Duration
+	for _, event := range events {
+		chart.MaxHeapMB = maxFloat(chart.MaxHeapMB, maxFloat(event.HeapTotalMB, event.HeapBeforeMB))
+		chart.MaxPauseMs = maxFloat(chart.MaxPauseMs, float64(event.Pause)/float64(time.Millisecond))
+		totalPause += event.Pause
+		if event.IsFull() {
+			chart.FullGCCount++
+		}
+		if event.IsLong() {
+			chart.LongPausesCount++
+		}
+	}
+	x := func(t time.Time) float64 {
+		if span == 0 {
+			return float64(chart.Width) / 2
+		}
+		return t.Sub(start).Seconds() / span * float64(chart.Width)
+	}
+	heapY := func(mb float64) float64 {
+		return float64(chart.Height) - mb/maxFloat(chart.MaxHeapMB, 1)*float64(chart.Height)
+	}
+	var totalPoints, afterPoints []string
+	location := getDisplayLocation()
+	for _, event := range events {
+		totalPoints = append(totalPoints, fmt.Sprintf("%.1f,%.1f", x(event.Time), heapY(event.HeapTotalMB)))
+		afterPoints = append(afterPoints, fmt.Sprintf("%.1f,%.1f", x(event.Time), heapY(event.HeapAfterMB)))
+		height := float64(event.Pause) / float64(time.Millisecond) / maxFloat(chart.MaxPauseMs, 1) * float64(chart.Height)
+		chart.Bars = append(chart.Bars, gcChartBar{
+			X:      x(event.Time),
+			Y:      float64(chart.Height) - height,
+			Height: height,
+			Long:   event.IsLong(),
+			Full:   event.IsFull(),
+			Title:  fmt.Sprintf("%s %s: %s, %.0fM->%.0fM(%.0fM)", event.Time.In(location).Format("02 Jan 2006 15:04:05"), event.Type, event.Pause, event.HeapBeforeMB, event.HeapAfterMB, event.HeapTotalMB),
+		})
+	}
+	chart.HeapTotalPoints = strings.Join(totalPoints, " ")
+	chart.HeapAfterPoints = strings.Join(afterPoints, " ")
+	chart.Start = start.In(location).Format("02 Jan 2006 15:04:05")
+	chart.End = end.In(location).Format("02 Jan 2006 15:04:05")
+	chart.PausesCount = len(events)
+	chart.TotalPause = totalPause.Round(time.Millisecond).String()
+
+	var tpl bytes.Buffer
+	t := template.Must(template.New("GCChart.gohtml").
+		ParseFS(tmplFS, "GCChart.gohtml"))
+	err := t.Execute(&tpl, chart)
+	if err != nil {
+		log.Printf("Template GCChart.gohtml execution failed. Error: %s", err.Error())
+	}
+	return tpl.String()
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
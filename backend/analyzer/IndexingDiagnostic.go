@@ -0,0 +1,420 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var indexingReportTimeRegex = regexp.MustCompile(`diagnostic-(\d{4}-\d{2}-\d{2}-\d{2}-\d{2}-\d{2})`)
+
+//SlowestIndexingContributorsCount is the number of indexers and file types shown for every indexing
+var SlowestIndexingContributorsCount = 5
+
+//indexingDiagnosticsCache keeps stats of every indexing report, they are parsed once for the logs, indexing events and the indexing tab
+var indexingDiagnosticsCache parseCache[parsedIndexingDiagnostic]
+
+type parsedIndexingDiagnostic struct {
+	stats IndexingStats
+	err   error
+}
+
+//IndexingStats is the summary of the indexing diagnostic (diagnostic-<date>.json written next to the html report)
+type IndexingStats struct {
+	ID                     string
+	Path                   string // Path of the html report, the data is read from the json file with the same name
+	Project                string
+	Time                   time.Time
+	Reason                 string
+	Type                   string // Type is "FULL" for full indexing, partial otherwise
+	Total                  time.Duration
+	Scanning               time.Duration
+	Indexing               time.Duration
+	ContentLoading         time.Duration
+	WasInterrupted         bool
+	FilesScanned           int
+	FilesIndexed           int
+	FilesFromSharedIndexes int // FilesFromSharedIndexes are the files whose indexes were taken from shared indexes instead of indexing
+	SlowestIndexers        []IndexingContributor
+	SlowestFileTypes       []IndexingContributor
+	HasTimeZone            bool // HasTimeZone is true if Time is taken from updatingStart with its offset, not from the name of the report
+}
+
+//IndexingContributor is an indexer or a file type and its part of the total indexing time
+type IndexingContributor struct {
+	Name  string
+	Part  float64 // Part of the total time, from 0 to 1
+	Files int
+}
+
+//IndexingHistory is a list of indexings sorted by time
+type IndexingHistory []IndexingStats
+
+func (s IndexingStats) SharedIndexesUsed() bool {
+	return s.FilesFromSharedIndexes > 0
+}
+
+//DisplayTime returns the time of the indexing in the time zone chosen for displaying
+func (s IndexingStats) DisplayTime() time.Time {
+	return s.Time.In(getDisplayLocation())
+}
+
+//Summary is a one-line description of the indexing
+func (s IndexingStats) Summary() string {
+	summary := fmt.Sprintf("total %s, scanning %s, indexing %s, files scanned: %d, indexed: %d", roundDuration(s.Total), roundDuration(s.Scanning), roundDuration(s.Indexing), s.FilesScanned, s.FilesIndexed)
+	if s.SharedIndexesUsed() {
+		summary += fmt.Sprintf(", from shared indexes: %d", s.FilesFromSharedIndexes)
+	} else {
+		summary += ", shared indexes were not used"
+	}
+	if s.WasInterrupted {
+		summary += ", interrupted"
+	}
+	return summary
+}
+
+//jsonDuration is a duration in nanoseconds. IDE versions write it either as a number or as {"nano": number}
+type jsonDuration time.Duration
+
+func (d *jsonDuration) UnmarshalJSON(data []byte) error {
+	var nanos float64
+	if err := json.Unmarshal(data, &nanos); err == nil {
+		*d = jsonDuration(nanos)
+		return nil
+	}
+	var object struct {
+		Nano float64 `json:"nano"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	*d = jsonDuration(object.Nano)
+	return nil
+}
+
+//jsonPercentage is a part of the total. IDE versions write it either as a number or as {"part": number, "total": number}
+type jsonPercentage float64
+
+func (p *jsonPercentage) UnmarshalJSON(data []byte) error {
+	var value float64
+	if err := json.Unmarshal(data, &value); err == nil {
+		*p = jsonPercentage(value)
+		return nil
+	}
+	var object struct {
+		Part  float64 `json:"part"`
+		Total float64 `json:"total"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	if object.Total > 0 {
+		*p = jsonPercentage(object.Part / object.Total)
+	}
+	return nil
+}
+
+//jsonDateTime is a time with offset written either as a string or as {"instant": string}.
+//Time that could not be parsed is logged and left zero, so the rest of the report is still used.
+type jsonDateTime time.Time
+
+func (t *jsonDateTime) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var object struct {
+			Instant string `json:"instant"`
+		}
+		if err := json.Unmarshal(data, &object); err != nil {
+			return err
+		}
+		s = object.Instant
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		log.Printf("Could not parse time '%s' of indexing diagnostic: %s", s, err)
+		return nil
+	}
+	*t = jsonDateTime(parsed.UTC())
+	return nil
+}
+
+type indexingDiagnosticJSON struct {
+	ProjectIndexingHistory *indexingHistoryJSON `json:"projectIndexingHistory"`
+}
+
+type indexingHistoryJSON struct {
+	ProjectName string `json:"projectName"`
+	Times       struct {
+		Reason             string       `json:"reason"`
+		IndexingType       string       `json:"indexingType"`
+		ScanningType       string       `json:"scanningType"`
+		UpdatingStart      jsonDateTime `json:"updatingStart"`
+		TotalUpdatingTime  jsonDuration `json:"totalUpdatingTime"`
+		IndexingTime       jsonDuration `json:"indexingTime"`
+		ScanFilesTime      jsonDuration `json:"scanFilesTime"`
+		ContentLoadingTime jsonDuration `json:"contentLoadingVisibleTime"`
+		WasInterrupted     bool         `json:"wasInterrupted"`
+	} `json:"times"`
+	FileCount struct {
+		NumberOfScannedFiles                                              int `json:"numberOfScannedFiles"`
+		NumberOfFilesIndexedByInfrastructureExtensionsDuringScan          int `json:"numberOfFilesIndexedByInfrastructureExtensionsDuringScan"`
+		NumberOfFilesScheduledForIndexingAfterScan                        int `json:"numberOfFilesScheduledForIndexingAfterScan"`
+		NumberOfFilesIndexedByInfrastructureExtensionsDuringIndexingStage int `json:"numberOfFilesIndexedByInfrastructureExtensionsDuringIndexingStage"`
+		NumberOfFilesIndexedWithLoadingContent                            int `json:"numberOfFilesIndexedWithLoadingContent"`
+	} `json:"fileCount"`
+	TotalStatsPerFileType []struct {
+		FileType                  string         `json:"fileType"`
+		PartOfTotalProcessingTime jsonPercentage `json:"partOfTotalProcessingTime"`
+		TotalNumberOfFiles        int            `json:"totalNumberOfFiles"`
+	} `json:"totalStatsPerFileType"`
+	TotalStatsPerIndexer []struct {
+		IndexID                 string         `json:"indexId"`
+		PartOfTotalIndexingTime jsonPercentage `json:"partOfTotalIndexingTime"`
+		TotalNumberOfFiles      int            `json:"totalNumberOfFiles"`
+	} `json:"totalStatsPerIndexer"`
+}
+
+//IndexingDiagnosticJSONPath returns the path of the json file with data of the html report
+func IndexingDiagnosticJSONPath(htmlPath string) string {
+	return strings.TrimSuffix(htmlPath, filepath.Ext(htmlPath)) + ".json"
+}
+
+//ParseIndexingDiagnostic reads diagnostic-<date>.json of the html report. Time of the indexing is taken from the file name if it is absent in the json.
+//The report is parsed once, the next calls return cached stats until the report changes.
+func ParseIndexingDiagnostic(htmlPath string) (IndexingStats, error) {
+	parsed := indexingDiagnosticsCache.get(htmlPath, func(htmlPath string) parsedIndexingDiagnostic {
+		stats, err := parseIndexingDiagnostic(htmlPath)
+		return parsedIndexingDiagnostic{stats: stats, err: err}
+	})
+	return parsed.stats, parsed.err
+}
+
+func parseIndexingDiagnostic(htmlPath string) (s IndexingStats, err error) {
+	s = IndexingStats{ID: getHash(htmlPath), Path: htmlPath, Project: indexingProjectFolder(htmlPath), Time: IndexingReportTime(htmlPath)}
+	content, err := ioutil.ReadFile(IndexingDiagnosticJSONPath(htmlPath))
+	if err != nil {
+		return s, err
+	}
+	var diagnostic indexingDiagnosticJSON
+	if err = json.Unmarshal(content, &diagnostic); err != nil {
+		return s, err
+	}
+	history := diagnostic.ProjectIndexingHistory
+	if history == nil {
+		return s, fmt.Errorf("no projectIndexingHistory in %s", IndexingDiagnosticJSONPath(htmlPath))
+	}
+	if len(history.ProjectName) > 0 {
+		s.Project = history.ProjectName
+	}
+	if start := time.Time(history.Times.UpdatingStart); !start.IsZero() {
+		s.Time = start
+		s.HasTimeZone = true
+	}
+	s.Reason = history.Times.Reason
+	s.Type = history.Times.IndexingType
+	if len(s.Type) == 0 {
+		s.Type = history.Times.ScanningType
+	}
+	s.Total = time.Duration(history.Times.TotalUpdatingTime)
+	s.Scanning = time.Duration(history.Times.ScanFilesTime)
+	s.Indexing = time.Duration(history.Times.IndexingTime)
+	s.ContentLoading = time.Duration(history.Times.ContentLoadingTime)
+	s.WasInterrupted = history.Times.WasInterrupted
+	s.FilesScanned = history.FileCount.NumberOfScannedFiles
+	s.FilesIndexed = history.FileCount.NumberOfFilesIndexedWithLoadingContent
+	s.FilesFromSharedIndexes = history.FileCount.NumberOfFilesIndexedByInfrastructureExtensionsDuringScan + history.FileCount.NumberOfFilesIndexedByInfrastructureExtensionsDuringIndexingStage
+	for _, indexer := range history.TotalStatsPerIndexer {
+		s.SlowestIndexers = append(s.SlowestIndexers, IndexingContributor{Name: indexer.IndexID, Part: float64(indexer.PartOfTotalIndexingTime), Files: indexer.TotalNumberOfFiles})
+	}
+	for _, fileType := range history.TotalStatsPerFileType {
+		s.SlowestFileTypes = append(s.SlowestFileTypes, IndexingContributor{Name: fileType.FileType, Part: float64(fileType.PartOfTotalProcessingTime), Files: fileType.TotalNumberOfFiles})
+	}
+	s.SlowestIndexers = slowestContributors(s.SlowestIndexers)
+	s.SlowestFileTypes = slowestContributors(s.SlowestFileTypes)
+	return s, nil
+}
+
+func slowestContributors(contributors []IndexingContributor) []IndexingContributor {
+	sort.SliceStable(contributors, func(i, j int) bool { return contributors[i].Part > contributors[j].Part })
+	if len(contributors) > SlowestIndexingContributorsCount {
+		contributors = contributors[:SlowestIndexingContributorsCount]
+	}
+	return contributors
+}
+
+//GetIndexingHistory returns stats of all indexings of the bundle. Analyzes them if it was not done already.
+func (a *Analyzer) GetIndexingHistory() IndexingHistory {
+	writeSyncer.Lock()
+	cached := a.AggregatedIndexingHistory
+	writeSyncer.Unlock()
+	if cached != nil {
+		return cached
+	}
+	history := IndexingHistory{}
+	for _, path := range a.GetIndexingFilesList() {
+		if filepath.Ext(path) != ".html" {
+			continue
+		}
+		stats, err := ParseIndexingDiagnostic(path)
+		if err != nil {
+			log.Printf("Could not parse indexing diagnostic for %s: %s", path, err)
+			continue
+		}
+		if !stats.HasTimeZone {
+			stats.Time = a.normalizeTime(stats.Time)
+		}
+		history = append(history, stats)
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].Time.Before(history[j].Time) })
+	writeSyncer.Lock()
+	a.AggregatedIndexingHistory = history
+	writeSyncer.Unlock()
+	return history
+}
+
+//IndexingReportTime returns the time from the name of the report, e.g. "diagnostic-2023-01-10-10-00-00.000.html"
+func IndexingReportTime(path string) time.Time {
+	match := indexingReportTimeRegex.FindStringSubmatch(filepath.Base(path))
+	if match == nil {
+		return time.Time{}
+	}
+	t, err := time.Parse("2006-01-02-15-04-05", match[1])
+	if err != nil {
+		log.Printf("IndexingReportTime failed. path: %s, error: %s", path, err)
+	}
+	return t
+}
+
+//indexingProjectFolder returns the name of the project from the folder of indexing diagnostic, e.g. "my-project" for "indexing-diagnostic/my-project.1a2b3c/diagnostic-....html"
+func indexingProjectFolder(path string) string {
+	folder := filepath.Base(filepath.Dir(path))
+	if idx := strings.LastIndex(folder, "."); idx > 0 {
+		return folder[:idx]
+	}
+	return folder
+}
+
+//FindByTime returns the indexing that was running at the moment
+func (history IndexingHistory) FindByTime(t time.Time) (stats IndexingStats, found bool) {
+	for _, stats := range history {
+		end := stats.Time.Add(maxDuration(stats.Total, stats.Scanning+stats.Indexing))
+		if !t.Before(stats.Time.Add(-time.Second)) && !t.After(end.Add(time.Second)) {
+			return stats, true
+		}
+	}
+	return stats, false
+}
+
+//ByProject groups indexings by project name, every group is sorted by time
+func (history IndexingHistory) ByProject() map[string]IndexingHistory {
+	projects := make(map[string]IndexingHistory)
+	for _, stats := range history {
+		projects[stats.Project] = append(projects[stats.Project], stats)
+	}
+	return projects
+}
+
+type indexingChart struct {
+	Project    string
+	Width      int
+	Height     int
+	Max        string
+	Start, End string
+	Bars       []indexingChartBar
+	Indexings  IndexingHistory
+}
+type indexingChartBar struct {
+	X, Width                  float64
+	ScanningY, ScanningHeight float64
+	IndexingY, IndexingHeight float64
+	Full                      bool
+	Problematic               bool
+	Title                     string
+}
+
+//ConvertToHTML renders the chart of indexing durations for every project and the stats of every indexing based on IndexingHistory.gohtml template
+func (history IndexingHistory) ConvertToHTML() string {
+	if len(history) == 0 {
+		return ""
+	}
+	findings := history.FindRegressions()
+	problematic := make(map[string]bool)
+	for _, finding := range findings {
+		problematic[finding.Indexing.ID] = true
+	}
+	var charts []indexingChart
+	for project, indexings := range history.ByProject() {
+		charts = append(charts, newIndexingChart(project, indexings, problematic))
+	}
+	sort.Slice(charts, func(i, j int) bool { return charts[i].Project < charts[j].Project })
+	var tpl bytes.Buffer
+	t := template.Must(template.New("IndexingHistory.gohtml").
+		Funcs(template.FuncMap{"percent": func(part float64) float64 { return part * 100 }, "base": filepath.Base}).
+		ParseFS(tmplFS, "IndexingHistory.gohtml"))
+	err := t.Execute(&tpl, struct {
+		Findings []IndexingFinding
+		Charts   []indexingChart
+	}{findings, charts})
+	if err != nil {
+		log.Printf("Template IndexingHistory.gohtml execution failed. Error: %s", err.Error())
+	}
+	return tpl.String()
+}
+
+//newIndexingChart positions indexings evenly, so bursts of indexings are readable. Height of the bar is the duration of scanning and indexing.
+func newIndexingChart(project string, indexings IndexingHistory, problematic map[string]bool) indexingChart {
+	chart := indexingChart{Project: project, Width: 600, Height: 120, Indexings: indexings}
+	var maxTotal time.Duration
+	for _, stats := range indexings {
+		if d := maxDuration(stats.Total, stats.Scanning+stats.Indexing); d > maxTotal {
+			maxTotal = d
+		}
+	}
+	chart.Max = roundDuration(maxTotal)
+	chart.Start = indexings[0].DisplayTime().Format("02 Jan 2006 15:04:05")
+	chart.End = indexings[len(indexings)-1].DisplayTime().Format("02 Jan 2006 15:04:05")
+	step := float64(chart.Width) / float64(len(indexings))
+	scale := func(d time.Duration) float64 {
+		if maxTotal == 0 {
+			return 0
+		}
+		return float64(d) / float64(maxTotal) * float64(chart.Height)
+	}
+	for i, stats := range indexings {
+		scanning, indexing := scale(stats.Scanning), scale(stats.Indexing)
+		chart.Bars = append(chart.Bars, indexingChartBar{
+			X:              float64(i)*step + step*0.1,
+			Width:          step * 0.8,
+			ScanningY:      float64(chart.Height) - scanning,
+			ScanningHeight: scanning,
+			IndexingY:      float64(chart.Height) - scanning - indexing,
+			IndexingHeight: indexing,
+			Full:           stats.Type == "FULL",
+			Problematic:    problematic[stats.ID],
+			Title:          stats.DisplayTime().Format("02 Jan 2006 15:04:05") + ": " + stats.Summary(),
+		})
+	}
+	return chart
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func roundDuration(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(100 * time.Millisecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
@@ -0,0 +1,134 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	SharedIndexEventsFileName         = "shared-index-events.json"
+	ChangedFilesPushingEventsFileName = "changed-files-pushing-events.json"
+)
+
+var (
+	//LargeChangedFilesPush is the number of files pushed to indexing after VFS refresh that is worth attention
+	LargeChangedFilesPush = 10000
+	//LongChangedFilesPush is the duration of pushing changed files to indexing that is worth attention
+	LongChangedFilesPush = 10 * time.Second
+)
+
+type sharedIndexEventJSON struct {
+	Type          string         `json:"type"`
+	Time          jsonDateTime   `json:"time"`
+	Kind          string         `json:"kind"`
+	ChunkUniqueID string         `json:"chunkUniqueId"`
+	FinishType    string         `json:"finishType"`
+	DownloadTime  jsonDuration   `json:"downloadTime"`
+	FbMatch       jsonPercentage `json:"fbMatch"`
+	StubMatch     jsonPercentage `json:"stubMatch"`
+}
+
+type changedFilesPushingEventJSON struct {
+	StartTime           jsonDateTime `json:"startTime"`
+	Duration            jsonDuration `json:"duration"`
+	Reason              string       `json:"reason"`
+	NumberOfPushedFiles int          `json:"numberOfPushedFiles"`
+	IsCancelled         bool         `json:"isCancelled"`
+}
+
+//IsIndexingEventsFile matches shared-index-events.json and changed-files-pushing-events.json of indexing diagnostic
+func IsIndexingEventsFile(path string) bool {
+	name := filepath.Base(path)
+	return name == SharedIndexEventsFileName || name == ChangedFilesPushingEventsFileName
+}
+
+//ParseIndexingEvents converts shared index and changed files pushing events to log entries.
+//Failed downloads and large pushes get WARN severity. Every entry references the indexing that was running at the moment of the event.
+func ParseIndexingEvents(path string, history IndexingHistory) (l Logs, err error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch filepath.Base(path) {
+	case SharedIndexEventsFileName:
+		var events []sharedIndexEventJSON
+		if err = json.Unmarshal(content, &events); err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			l = append(l, event.convertToLogEntry())
+		}
+	case ChangedFilesPushingEventsFileName:
+		var events []changedFilesPushingEventJSON
+		if err = json.Unmarshal(content, &events); err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			l = append(l, event.convertToLogEntry())
+		}
+	default:
+		return nil, fmt.Errorf("%s is not an indexing events file", path)
+	}
+	for i := range l {
+		if stats, found := history.FindByTime(l[i].Time); found {
+			l[i].Text = l[i].Text + ". Indexing report: " + filepath.Base(stats.Path)
+		}
+	}
+	return l, nil
+}
+
+func (e sharedIndexEventJSON) convertToLogEntry() LogEntry {
+	text := "Shared index"
+	if len(e.Kind) > 0 {
+		text = text + " (" + e.Kind + ")"
+	}
+	text = text + " " + strings.ToLower(e.Type)
+	if len(e.ChunkUniqueID) > 0 {
+		text = text + ": " + e.ChunkUniqueID
+	}
+	if len(e.FinishType) > 0 {
+		text = text + ", result: " + e.FinishType
+	}
+	if e.DownloadTime > 0 {
+		text = text + ", download time: " + roundDuration(time.Duration(e.DownloadTime))
+	}
+	if e.FbMatch > 0 || e.StubMatch > 0 {
+		text = text + fmt.Sprintf(", matched files: %.0f%%, stubs: %.0f%%", float64(e.FbMatch)*100, float64(e.StubMatch)*100)
+	}
+	severity := "INDEX"
+	result := strings.ToUpper(e.FinishType + " " + e.Type)
+	if strings.Contains(result, "FAIL") || strings.Contains(result, "CANCEL") || strings.Contains(result, "ERROR") {
+		severity = "WARN"
+	}
+	return LogEntry{
+		Severity:    severity,
+		Time:        time.Time(e.Time),
+		Text:        text,
+		HasTimeZone: !time.Time(e.Time).IsZero(),
+	}
+}
+
+func (e changedFilesPushingEventJSON) convertToLogEntry() LogEntry {
+	duration := time.Duration(e.Duration)
+	text := fmt.Sprintf("Changed files pushed to indexing: %d files in %s", e.NumberOfPushedFiles, roundDuration(duration))
+	if len(e.Reason) > 0 {
+		text = text + ", reason: " + e.Reason
+	}
+	if e.IsCancelled {
+		text = text + ", cancelled"
+	}
+	severity := "INDEX"
+	if e.NumberOfPushedFiles >= LargeChangedFilesPush || duration >= LongChangedFilesPush {
+		severity = "WARN"
+	}
+	return LogEntry{
+		Severity:    severity,
+		Time:        time.Time(e.StartTime),
+		Text:        text,
+		HasTimeZone: !time.Time(e.StartTime).IsZero(),
+	}
+}
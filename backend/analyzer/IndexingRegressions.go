@@ -0,0 +1,127 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+var (
+	//SlowIndexingFactor is how many times an indexing should be slower than the median of the project to be reported
+	SlowIndexingFactor = 2.0
+	//MinReportedIndexingDuration filters out short indexings, they are not worth attention even if they are slower than usual
+	MinReportedIndexingDuration = 30 * time.Second
+	//DominantIndexingShare is the part of indexing time taken by a single indexer or file type that is considered abnormal
+	DominantIndexingShare = 0.5
+	//MinIndexingsForBaseline is the number of indexings of the same type required to calculate the baseline
+	MinIndexingsForBaseline = 3
+)
+
+//IndexingFinding is an indexing that is slower than usual or dominated by a single indexer or file type, with its likely cause
+type IndexingFinding struct {
+	Indexing IndexingStats
+	Problem  string
+	Cause    string
+}
+
+//FindRegressions compares every indexing with the median duration of the indexings of the same project and type
+func (history IndexingHistory) FindRegressions() (findings []IndexingFinding) {
+	for _, indexings := range history.ByProject() {
+		byType := make(map[string]IndexingHistory)
+		for _, stats := range indexings {
+			byType[stats.Type] = append(byType[stats.Type], stats)
+		}
+		for _, sameType := range byType {
+			baseline := sameType.baseline()
+			for _, stats := range sameType {
+				if finding, found := stats.checkRegression(baseline); found {
+					findings = append(findings, finding)
+				}
+			}
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Indexing.Time.Before(findings[j].Indexing.Time) })
+	return findings
+}
+
+//indexingBaseline is the usual indexing of the project
+type indexingBaseline struct {
+	Total             time.Duration
+	FilesIndexed      int
+	SharedIndexesUsed bool
+	Known             bool // Known is false if there are not enough indexings to calculate the baseline
+}
+
+func (history IndexingHistory) baseline() (b indexingBaseline) {
+	if len(history) < MinIndexingsForBaseline {
+		return b
+	}
+	totals := make([]time.Duration, 0, len(history))
+	files := make([]int, 0, len(history))
+	sharedIndexesUsed := 0
+	for _, stats := range history {
+		totals = append(totals, stats.duration())
+		files = append(files, stats.FilesIndexed)
+		if stats.SharedIndexesUsed() {
+			sharedIndexesUsed++
+		}
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i] < totals[j] })
+	sort.Ints(files)
+	return indexingBaseline{
+		Total:             totals[len(totals)/2],
+		FilesIndexed:      files[len(files)/2],
+		SharedIndexesUsed: sharedIndexesUsed*2 > len(history),
+		Known:             true,
+	}
+}
+
+func (s IndexingStats) duration() time.Duration {
+	return maxDuration(s.Total, s.Scanning+s.Indexing)
+}
+
+func (s IndexingStats) checkRegression(baseline indexingBaseline) (finding IndexingFinding, found bool) {
+	if s.duration() < MinReportedIndexingDuration {
+		return finding, false
+	}
+	finding.Indexing = s
+	dominant := s.dominantContributor()
+	switch {
+	case baseline.Known && float64(s.duration()) > SlowIndexingFactor*float64(baseline.Total):
+		finding.Problem = fmt.Sprintf("%s is %.1f times slower than usual %s", roundDuration(s.duration()), float64(s.duration())/float64(maxDuration(baseline.Total, 1)), roundDuration(baseline.Total))
+		finding.Cause = s.likelyCause(baseline, dominant)
+	case len(dominant) > 0:
+		finding.Problem = "Indexing is dominated by a single contributor"
+		finding.Cause = dominant
+	default:
+		return finding, false
+	}
+	return finding, true
+}
+
+//dominantContributor describes the indexer or the file type that takes more than DominantIndexingShare of the time
+func (s IndexingStats) dominantContributor() string {
+	if len(s.SlowestIndexers) > 0 && s.SlowestIndexers[0].Part >= DominantIndexingShare {
+		return fmt.Sprintf("indexer %s takes %.0f%% of indexing time", s.SlowestIndexers[0].Name, s.SlowestIndexers[0].Part*100)
+	}
+	if len(s.SlowestFileTypes) > 0 && s.SlowestFileTypes[0].Part >= DominantIndexingShare {
+		return fmt.Sprintf("file type %s takes %.0f%% of processing time (%d files)", s.SlowestFileTypes[0].Name, s.SlowestFileTypes[0].Part*100, s.SlowestFileTypes[0].Files)
+	}
+	return ""
+}
+
+func (s IndexingStats) likelyCause(baseline indexingBaseline, dominant string) string {
+	switch {
+	case baseline.SharedIndexesUsed && !s.SharedIndexesUsed():
+		return "shared indexes were not used, though they are usually used for this project"
+	case baseline.FilesIndexed > 0 && float64(s.FilesIndexed) > SlowIndexingFactor*float64(baseline.FilesIndexed):
+		return fmt.Sprintf("%d files were indexed instead of usual %d", s.FilesIndexed, baseline.FilesIndexed)
+	case len(dominant) > 0:
+		return dominant
+	case s.Scanning > s.Indexing:
+		return fmt.Sprintf("scanning took %s, more than indexing itself. Check excluded folders and file system performance", roundDuration(s.Scanning))
+	case s.WasInterrupted:
+		return "indexing was interrupted"
+	}
+	return "unknown, see the report for details"
+}
@@ -0,0 +1,200 @@
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+//MinRecommendedXmxMB is the heap size below which IDE is expected to run out of memory on average projects
+var MinRecommendedXmxMB = 1024
+
+var (
+	knownGarbageCollectors = map[string]string{
+		"UseG1GC":             "G1",
+		"UseParallelGC":       "Parallel",
+		"UseSerialGC":         "Serial",
+		"UseConcMarkSweepGC":  "CMS",
+		"UseZGC":              "ZGC",
+		"UseShenandoahGC":     "Shenandoah",
+		"UseEpsilonGC":        "Epsilon",
+		"UseParallelOldGC":    "Parallel",
+		"UseConcurrentMarkGC": "CMS",
+	}
+	//knownJavaAgents are parts of -javaagent paths that are shipped with IDE or well-known profilers
+	knownJavaAgents = []string{"idea_rt", "debugger-agent", "yjpagent", "async-profiler", "jetbrains", "JetBrains", "intellij"}
+)
+
+//JVMSettings is the summary of *.vmoptions and idea.properties files
+type JVMSettings struct {
+	VMOptionsFile      string
+	PropertiesFile     string
+	VMOptionsPath      string `json:"-"` // VMOptionsPath and PropertiesPath are full paths of the files, they decide which file wins when several are found
+	PropertiesPath     string `json:"-"`
+	Xmx                string
+	Xms                string
+	GC                 string
+	Agents             []string
+	Options            []string
+	OverriddenIdeaPath map[string]string // OverriddenIdeaPath contains idea.*.path properties, for example idea.system.path
+	Warnings           []string
+}
+
+func (s JVMSettings) IsEmpty() bool {
+	return len(s.VMOptionsFile) == 0 && len(s.PropertiesFile) == 0
+}
+
+//ReadOptionsFile returns non-empty lines of vmoptions/properties file without comments
+func ReadOptionsFile(path string) (options []string) {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Printf("ReadOptionsFile failed. path: %s, error: %s", path, err)
+		return nil
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		option := strings.TrimSpace(scanner.Text())
+		if len(option) != 0 && option[0] != '#' {
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+//ParseVMOptions extracts heap sizes, garbage collector and agents from the list of JVM options and checks them for suspicious values
+func ParseVMOptions(options []string) (s JVMSettings) {
+	s.Options = options
+	seen := make(map[string]string)
+	var collectors []string
+	for _, option := range options {
+		key := VMOptionKey(option)
+		if previous, ok := seen[key]; ok {
+			s.Warnings = append(s.Warnings, fmt.Sprintf("Option is defined several times: \"%s\" and \"%s\". JVM uses the last one", previous, option))
+		}
+		seen[key] = option
+		switch {
+		case strings.HasPrefix(option, "-Xmx"):
+			s.Xmx = strings.TrimPrefix(option, "-Xmx")
+		case strings.HasPrefix(option, "-Xms"):
+			s.Xms = strings.TrimPrefix(option, "-Xms")
+		case strings.HasPrefix(option, "-XX:+Use") && strings.HasSuffix(option, "GC"):
+			if gc, ok := knownGarbageCollectors[strings.TrimPrefix(option, "-XX:+")]; ok {
+				collectors = append(collectors, gc)
+			}
+		case strings.HasPrefix(option, "-javaagent:"), strings.HasPrefix(option, "-agentpath:"), strings.HasPrefix(option, "-agentlib:"):
+			s.Agents = append(s.Agents, option)
+			if strings.HasPrefix(option, "-javaagent:") && !isKnownJavaAgent(option) {
+				s.Warnings = append(s.Warnings, "Unknown java agent: "+option)
+			}
+		}
+	}
+	switch len(collectors) {
+	case 0:
+		s.GC = "default"
+	case 1:
+		s.GC = collectors[0]
+	default:
+		s.GC = strings.Join(collectors, ", ")
+		s.Warnings = append(s.Warnings, "Several garbage collectors are selected: "+s.GC)
+	}
+	xmx, xmxErr := parseMemorySizeMB(s.Xmx)
+	xms, xmsErr := parseMemorySizeMB(s.Xms)
+	if len(s.Xmx) > 0 && xmxErr == nil && xmx < MinRecommendedXmxMB {
+		s.Warnings = append(s.Warnings, fmt.Sprintf("Xmx is %s, less than %d MB is not enough for most projects", s.Xmx, MinRecommendedXmxMB))
+	}
+	if xmxErr == nil && xmsErr == nil && xms > xmx {
+		s.Warnings = append(s.Warnings, fmt.Sprintf("Xms (%s) is bigger than Xmx (%s)", s.Xms, s.Xmx))
+	}
+	return s
+}
+
+//ParseIdeaProperties collects overridden idea.*.path properties
+func ParseIdeaProperties(properties map[string]string) (s JVMSettings) {
+	s.OverriddenIdeaPath = make(map[string]string)
+	for name, value := range properties {
+		if strings.HasPrefix(name, "idea.") && strings.HasSuffix(name, ".path") {
+			s.OverriddenIdeaPath[name] = value
+		}
+	}
+	return s
+}
+
+//merge takes vmoptions and properties of another file if it overrides the current one, see overridesOptionsFile.
+//Files are parsed in parallel, so the result does not depend on the order merge is called in.
+func (s *JVMSettings) merge(other JVMSettings) {
+	if len(other.VMOptionsFile) > 0 && (len(s.VMOptionsFile) == 0 || overridesOptionsFile(other.VMOptionsPath, s.VMOptionsPath)) {
+		propertiesFile, propertiesPath, overriddenPaths := s.PropertiesFile, s.PropertiesPath, s.OverriddenIdeaPath
+		*s = other
+		s.PropertiesFile, s.PropertiesPath, s.OverriddenIdeaPath = propertiesFile, propertiesPath, overriddenPaths
+	}
+	if len(other.PropertiesFile) > 0 && (len(s.PropertiesFile) == 0 || overridesOptionsFile(other.PropertiesPath, s.PropertiesPath)) {
+		s.PropertiesFile, s.PropertiesPath, s.OverriddenIdeaPath = other.PropertiesFile, other.PropertiesPath, other.OverriddenIdeaPath
+	}
+}
+
+//overridesOptionsFile checks if the file is used by IDE instead of another one. The file of the user (in the config directory)
+//wins over the file shipped in bin folder of the installation, otherwise the files are ordered by path.
+func overridesOptionsFile(path string, other string) bool {
+	if isShipped, isOtherShipped := isShippedOptionsFile(path), isShippedOptionsFile(other); isShipped != isOtherShipped {
+		return isOtherShipped
+	}
+	return path < other
+}
+
+func isShippedOptionsFile(path string) bool {
+	return strings.EqualFold(filepath.Base(filepath.Dir(path)), "bin")
+}
+
+//VMOptionKey returns the part of JVM option that identifies it, so duplicates with different values could be found
+func VMOptionKey(option string) string {
+	switch {
+	case strings.HasPrefix(option, "-Xmx"), strings.HasPrefix(option, "-Xms"), strings.HasPrefix(option, "-Xss"):
+		return option[:4]
+	case strings.HasPrefix(option, "-XX:+"), strings.HasPrefix(option, "-XX:-"):
+		return "-XX:" + option[5:]
+	case strings.HasPrefix(option, "-XX:"), strings.HasPrefix(option, "-D"):
+		if idx := strings.IndexByte(option, '='); idx >= 0 {
+			return option[:idx]
+		}
+	}
+	return option
+}
+
+func isKnownJavaAgent(option string) bool {
+	for _, agent := range knownJavaAgents {
+		if strings.Contains(option, agent) {
+			return true
+		}
+	}
+	return false
+}
+
+//parseMemorySizeMB converts JVM memory size (2048m, 2g, 750M, 1048576k) to megabytes
+func parseMemorySizeMB(size string) (int, error) {
+	size = strings.TrimSpace(size)
+	if len(size) == 0 {
+		return 0, fmt.Errorf("empty memory size")
+	}
+	multiplier := 1.0 / (1024 * 1024)
+	switch strings.ToLower(size[len(size)-1:]) {
+	case "k":
+		multiplier = 1.0 / 1024
+		size = size[:len(size)-1]
+	case "m":
+		multiplier = 1
+		size = size[:len(size)-1]
+	case "g":
+		multiplier = 1024
+		size = size[:len(size)-1]
+	}
+	value, err := strconv.ParseFloat(size, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int(value * multiplier), nil
+}
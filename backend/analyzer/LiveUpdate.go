@@ -4,88 +4,370 @@ import (
 	"github.com/nxadm/tail"
 	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
 	"io"
+	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
 )
 
+//folderPollInterval is how often the analyzed folder is checked for new files during live update
+const folderPollInterval = 2 * time.Second
+
+//entryFlushDelay is how long a tailer waits for continuation lines (e.g. stack trace) before the entry is considered complete
+const entryFlushDelay = 300 * time.Millisecond
+
+//Log rotation renames the log either keeping the extension (idea.log to idea.1.log) or appending the number (build.log to build.log.1)
+var (
+	rotatedLogRegex         = regexp.MustCompile(`^(?P<Base>.+)\.\d+(?P<Ext>\.[^.\d][^.]*)$`)
+	rotatedLogSuffixedRegex = regexp.MustCompile(`^(?P<Name>.+)\.\d+$`)
+)
+
+//liveUpdateMutex guards Analyzer.liveUpdate, the session is started from UI and stopped when analyzer is cleared
+var liveUpdateMutex sync.Mutex
+
+//liveUpdate is the live update session of the analyzed folder. Its owner goroutine is the only one changing logs while the session runs:
+//tailers send complete entries over the entries channel, new files found by folderWatcher are collected on owner's ticks.
+type liveUpdate struct {
+	entries    chan liveEntry
+	stop       chan struct{}
+	ownerDone  chan struct{}
+	readers    sync.WaitGroup
+	tails      []*tail.Tail // tails is changed by the owner goroutine only, other goroutines read it under tailsMutex
+	tailsMutex sync.Mutex
+	folder     *folderWatcher
+}
+
+//liveEntry is the text of one log entry read by the tailer of the entity instance
+type liveEntry struct {
+	entityIndex int
+	path        string
+	text        string
+}
+
 func (a *Analyzer) EnableLogsLiveUpdate() {
-	if len(a.fileWatchers) > 0 {
+	liveUpdateMutex.Lock()
+	defer liveUpdateMutex.Unlock()
+	if a.liveUpdate != nil {
 		log.Println("Logs live update already enabled")
-		for _, watcher := range a.fileWatchers {
-			log.Printf("file watcher: %v", watcher)
+		for _, filename := range a.liveUpdate.tailedFiles() {
+			log.Printf("file watcher: %v", filename)
 		}
 		return
 	}
+	s := &liveUpdate{
+		entries:   make(chan liveEntry),
+		stop:      make(chan struct{}),
+		ownerDone: make(chan struct{}),
+		folder:    newFolderWatcher(a.FolderToWorkWith),
+	}
 	for entityIndex, entity := range a.DynamicEntities {
 		for path, instanceProperties := range entity.entityInstances {
 			if instanceProperties.Visible {
-				if entity.GetChangeablePath != nil {
-					if changeablePath := entity.GetChangeablePath(path); changeablePath != "" {
-						if s, err := os.Stat(changeablePath); !s.IsDir() && err == nil && entity.ConvertStringToLogs != nil {
-							go a.addWatcher(changeablePath, entityIndex)
-						}
-					}
-				}
+				a.tailInstance(s, entityIndex, path)
 			}
 		}
 	}
+	a.liveUpdate = s
+	go a.runLiveUpdate(s)
+	log.Printf("Enabled folder watcher for: %s", a.FolderToWorkWith)
 }
 
-func (a *Analyzer) addWatcher(logFile string, entityIndex int) {
-	for _, watcher := range a.fileWatchers {
-		if watcher.Filename == logFile {
+//stopLiveUpdate stops the session and waits until all its goroutines exit
+func (a *Analyzer) stopLiveUpdate() {
+	liveUpdateMutex.Lock()
+	defer liveUpdateMutex.Unlock()
+	s := a.liveUpdate
+	if s == nil {
+		return
+	}
+	close(s.stop)
+	<-s.ownerDone
+	for _, t := range s.tails {
+		if err := t.Stop(); err != nil {
+			log.Printf("Stopping file watcher for %s failed. Error: %s", t.Filename, err)
+		}
+	}
+	s.readers.Wait()
+	a.liveUpdate = nil
+}
+
+//runLiveUpdate is the owner goroutine of the session
+func (a *Analyzer) runLiveUpdate(s *liveUpdate) {
+	defer close(s.ownerDone)
+	ticker := time.NewTicker(folderPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
 			return
+		case entry := <-s.entries:
+			a.attachToLogsStruct(entry)
+		case <-ticker.C:
+			if added := s.folder.scan(); len(added) > 0 {
+				a.collectAddedFiles(s, added)
+			}
 		}
 	}
+}
+
+//tailInstance starts tailing of the entity instance if it is a simple log file
+func (a *Analyzer) tailInstance(s *liveUpdate, entityIndex int, path string) {
+	entity := a.DynamicEntities[entityIndex]
+	if entity.GetChangeablePath == nil || entity.ConvertStringToLogs == nil {
+		return
+	}
+	logFile := entity.GetChangeablePath(path)
+	if logFile == "" {
+		return
+	}
+	if info, err := os.Stat(logFile); err != nil || info.IsDir() {
+		return
+	}
+	for _, filename := range s.tailedFiles() {
+		if filename == logFile {
+			return
+		}
+	}
+	// ReOpen follows the file when it is rotated (renamed and created again) or truncated
 	t, err := tail.TailFile(logFile, tail.Config{
 		Follow:   true,
+		ReOpen:   true,
 		Poll:     true,
-		Location: &tail.SeekInfo{Offset: 0, Whence: io.SeekEnd}, // <- line changed
+		Location: &tail.SeekInfo{Offset: 0, Whence: io.SeekEnd},
 	})
 	if err != nil {
 		log.Println(err)
+		return
 	}
-	a.fileWatchers = append(a.fileWatchers, t)
+	s.tailsMutex.Lock()
+	s.tails = append(s.tails, t)
+	s.tailsMutex.Unlock()
+	s.readers.Add(1)
+	go s.readEntries(t, entity.ConvertStringToLogs, entityIndex, path)
 	log.Printf("Enabled File watcher for: %v", logFile)
+}
 
-	previousLogEntry := ""
-	// t.Lines channel sends new lines of a file to "for" loop.
-	// Inside the loop, lines are being checked by ConvertStringToLogs function.
-	// If a line could be converted to log entry (ConvertStringToLogs return nil error), it is being remembered as previousLogEntry.
-	// If [the next line is also a log entry],  previousLogEntry is being appended to the logs entry list. If next line is the last line of file, it also is being appended to the logs entry list.
-	// If [the next line is not a log entry], it is being appended to the previous log line (as it is part of the same log entry).
-	for line := range t.Lines {
-		f, _ := os.Open(t.Filename)
-		seek, _ := f.Seek(0, 2)
-		lineIsLast := line.SeekInfo.Offset == seek
-		if _, err := a.DynamicEntities[entityIndex].ConvertStringToLogs(line.Text); err == nil {
-			if len(previousLogEntry) != 0 {
-				a.attachToLogsStruct(previousLogEntry, entityIndex, t.Filename)
+// readEntries joins lines of the tailed file into log entries and sends them to the owner goroutine.
+// A line that can be converted to log entry starts a new entry, other lines are appended to the current one (as they are part of the same log entry).
+// The entry is sent when the next one starts or when no lines come during entryFlushDelay.
+func (s *liveUpdate) readEntries(t *tail.Tail, convert func(string) (LogEntry, error), entityIndex int, path string) {
+	defer s.readers.Done()
+	current := ""
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		select {
+		case s.entries <- liveEntry{entityIndex: entityIndex, path: path, text: current}:
+		case <-s.stop:
+		}
+		current = ""
+	}
+	timer := time.NewTimer(entryFlushDelay)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case line, ok := <-t.Lines:
+			if !ok {
+				flush()
+				return
 			}
-			if lineIsLast {
-				a.attachToLogsStruct(line.Text, entityIndex, t.Filename)
-				previousLogEntry = ""
+			if line.Err != nil {
+				log.Printf("Tailing %s: %s", t.Filename, line.Err)
+				continue
+			}
+			if _, err := convert(line.Text); err == nil || len(current) == 0 {
+				flush()
+				current = line.Text
 			} else {
-				previousLogEntry = line.Text
+				current = current + "\n" + line.Text
+			}
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
 			}
+			timer.Reset(entryFlushDelay)
+		case <-timer.C:
+			flush()
+		}
+	}
+}
+
+func (a *Analyzer) attachToLogsStruct(entry liveEntry) {
+	entity := a.DynamicEntities[entry.entityIndex]
+	l, e := entity.ConvertStringToLogs(entry.text)
+	if e != nil {
+		log.Printf("Cannot convert string to logs: %s \n string: %s", e, entry.text)
+		return
+	}
+	a.normalizeEntryTime(&l)
+	writeSyncer.Lock()
+	l = a.AggregatedLogs.Insert(entity.Name, entity.entityInstances[entry.path], l)
+	if limit := GetParsingSettings().MaxLogEntries; limit > 0 && len(a.AggregatedLogs) > limit {
+		a.AggregatedLogs = a.AggregatedLogs[len(a.AggregatedLogs)-limit:]
+	}
+	writeSyncer.Unlock()
+	if l.Visible {
+		a.emitEvent("LogsUpdated", l.ConvertToHTML())
+	}
+	a.raiseEntryAlerts(l)
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+//folderWatcher finds files and folders added to the analyzed folder, for example new thread dumps or indexing reports.
+//A new path is reported once it stays unchanged for one poll, so half-written reports are not parsed. Files of a new folder are reported after the folder itself.
+type folderWatcher struct {
+	folder  string
+	known   map[string]bool
+	pending map[string]fileState
+}
+
+func newFolderWatcher(folder string) *folderWatcher {
+	w := &folderWatcher{folder: folder, known: make(map[string]bool), pending: make(map[string]fileState)}
+	_ = filepath.WalkDir(folder, func(path string, d fs.DirEntry, err error) error {
+		w.known[path] = true
+		return nil
+	})
+	return w
+}
+
+//scan returns new paths that did not change since the previous scan. Parent folders go before their files.
+func (w *folderWatcher) scan() (added []string) {
+	seen := make(map[string]bool)
+	_ = filepath.WalkDir(w.folder, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || w.known[path] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		seen[path] = true
+		state := fileState{size: info.Size(), modTime: info.ModTime()}
+		if previous, found := w.pending[path]; found && previous == state && w.known[filepath.Dir(path)] {
+			w.known[path] = true
+			delete(w.pending, path)
+			added = append(added, path)
 		} else {
-			previousLogEntry = previousLogEntry + "\n" + line.Text
-			if lineIsLast {
-				a.attachToLogsStruct(previousLogEntry, entityIndex, t.Filename)
-				previousLogEntry = ""
+			w.pending[path] = state
+		}
+		return nil
+	})
+	for path := range w.pending {
+		if !seen[path] {
+			delete(w.pending, path)
+		}
+	}
+	return added
+}
+
+//collectAddedFiles parses files found by the folder watcher, updates filters and notifies UI with "FilesAdded" event
+func (a *Analyzer) collectAddedFiles(s *liveUpdate, paths []string) {
+	var addedNames []string
+	for _, path := range paths {
+		if s.isRotatedLog(path) {
+			log.Printf("%s is a rotated copy of the tailed log, skipping it", path)
+			continue
+		}
+		a.raiseFileAlerts(path)
+		if !a.CollectLogsFromDynamicEntities(path) {
+			// Files of collected folders (for example threadDumps-freeze-*) are not other files, the same way as on the initial parse
+			if a.isEntityInstance(filepath.Dir(path)) {
+				continue
+			}
+			if info, err := os.Stat(path); err == nil && !info.IsDir() && !IsHiddenFile(filepath.Base(path)) {
+				writeSyncer.Lock()
+				a.OtherFiles.Append(path)
+				writeSyncer.Unlock()
+			}
+			continue
+		}
+		for i, entity := range a.DynamicEntities {
+			if _, found := entity.entityInstances[path]; !found {
+				continue
+			}
+			writeSyncer.Lock()
+			a.Filters.Append(entity, path)
+			writeSyncer.Unlock()
+			addedNames = append(addedNames, entity.GetDisplayName(path))
+			if entity.entityInstances[path].Visible {
+				a.tailInstance(s, i, path)
 			}
 		}
+	}
+	if len(addedNames) == 0 {
+		return
+	}
+	log.Printf("Live update found new files: %v", addedNames)
+	writeSyncer.Lock()
+	a.Filters.SortByFilename()
+	a.AggregatedLogs.SortByTime()
+	a.AggregatedGCEvents = nil
+	a.AggregatedCrashReports = nil
+	a.AggregatedPluginErrors = nil
+	a.AggregatedIndexingHistory = nil
+	writeSyncer.Unlock()
+	a.GetPluginErrors()
+	a.emitEvent("FilesAdded", addedNames)
+}
 
+//isEntityInstance checks if the path is collected by one of dynamic entities
+func (a *Analyzer) isEntityInstance(path string) bool {
+	writeSyncer.Lock()
+	defer writeSyncer.Unlock()
+	for _, entity := range a.DynamicEntities {
+		if _, found := entity.entityInstances[path]; found {
+			return true
+		}
 	}
+	return false
 }
-func (a *Analyzer) attachToLogsStruct(s string, i int, path string) {
-	name := a.DynamicEntities[i].Name
-	properties := a.DynamicEntities[i].entityInstances[path]
-	l, e := a.DynamicEntities[i].ConvertStringToLogs(s)
-	if e == nil {
-		a.AggregatedLogs.Append(name, properties, l)
-		wailsruntime.EventsEmit(*a.Context, "LogsUpdated", l.ConvertToHTML())
-	} else {
-		log.Printf("Cannot convert string to logs: %s \n string: %s", e, s)
+
+//isRotatedLog checks if the file is a rotated copy of a tailed log. Its entries are already collected while the log was tailed.
+func (s *liveUpdate) isRotatedLog(path string) bool {
+	var originals []string
+	name := filepath.Base(path)
+	if match := rotatedLogRegex.FindStringSubmatch(name); match != nil {
+		originals = append(originals, match[rotatedLogRegex.SubexpIndex("Base")]+match[rotatedLogRegex.SubexpIndex("Ext")])
+	}
+	if match := rotatedLogSuffixedRegex.FindStringSubmatch(name); match != nil {
+		originals = append(originals, match[rotatedLogSuffixedRegex.SubexpIndex("Name")])
+	}
+	for _, filename := range s.tailedFiles() {
+		for _, original := range originals {
+			if filename == filepath.Join(filepath.Dir(path), original) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//tailedFiles returns names of the files tailed by the session
+func (s *liveUpdate) tailedFiles() (filenames []string) {
+	s.tailsMutex.Lock()
+	defer s.tailsMutex.Unlock()
+	for _, t := range s.tails {
+		filenames = append(filenames, t.Filename)
+	}
+	return filenames
+}
+
+//emitEvent sends the event to UI. Does nothing if analyzer is used without UI.
+func (a *Analyzer) emitEvent(name string, data ...interface{}) {
+	if a.Context != nil {
+		wailsruntime.EventsEmit(*a.Context, name, data...)
 	}
 }
@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testLogLineRegex = regexp.MustCompile(`^(\d{4}-\d\d-\d\d \d\d:\d\d:\d\d,\d{3}) (\w+) `)
+	testLogNameRegex = regexp.MustCompile(`^test(\.\d+)?\.log$`) // testLogNameRegex accepts rotated logs the same way as idea.log entity
+)
+
+func convertTestLogLine(s string) (LogEntry, error) {
+	match := testLogLineRegex.FindStringSubmatch(s)
+	if match == nil {
+		return LogEntry{}, errors.New("not a log line")
+	}
+	t, err := time.Parse("2006-01-02 15:04:05,000", match[1])
+	if err != nil {
+		return LogEntry{}, err
+	}
+	return LogEntry{Severity: match[2], Time: t, Text: s}, nil
+}
+
+func newTestLogEntity() DynamicEntity {
+	return DynamicEntity{
+		Name: "Test Log",
+		ConvertPathToLogs: func(path string) (logs Logs) {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil
+			}
+			for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+				if entry, err := convertTestLogLine(line); err == nil {
+					logs = append(logs, entry)
+				}
+			}
+			return logs
+		},
+		ConvertStringToLogs: convertTestLogLine,
+		GetChangeablePath:   func(path string) string { return path },
+		CheckPath:           func(path string) bool { return testLogNameRegex.MatchString(filepath.Base(path)) },
+		GetDisplayName:      filepath.Base,
+	}
+}
+
+func TestLiveUpdate(t *testing.T) {
+	folder := t.TempDir()
+	logFile := filepath.Join(folder, "test.log")
+	initial := "2022-01-01 10:00:00,000 INFO first\n2022-01-01 10:00:05,000 INFO last\n"
+	if err := os.WriteFile(logFile, []byte(initial), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a := &Analyzer{FolderToWorkWith: folder}
+	a.AddDynamicEntity(newTestLogEntity())
+	a.ParseLogDirectory(folder)
+	a.GenerateFilters()
+	goroutinesBefore := runtime.NumGoroutine()
+
+	a.EnableLogsLiveUpdate()
+	// the tailer seeks to the end of the file asynchronously
+	time.Sleep(time.Second)
+	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appended := "2022-01-01 10:00:03,000 ERROR failed\njava.lang.RuntimeException\n\tat Foo.bar(Foo.java:1)\n2022-01-01 10:00:01,000 INFO second\n"
+	if _, err := file.WriteString(appended); err != nil {
+		t.Fatal(err)
+	}
+	_ = file.Close()
+
+	var logs Logs
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		logs = a.GetVisibleLogs()
+		if len(logs) == 4 {
+			break
+		}
+	}
+	if len(logs) != 4 {
+		t.Fatalf("expected 4 entries after live update, got %d: %v", len(logs), logs)
+	}
+	expected := []string{
+		"2022-01-01 10:00:00,000 INFO first",
+		"2022-01-01 10:00:01,000 INFO second",
+		"2022-01-01 10:00:03,000 ERROR failed\njava.lang.RuntimeException\n\tat Foo.bar(Foo.java:1)",
+		"2022-01-01 10:00:05,000 INFO last",
+	}
+	for i, entry := range logs {
+		if entry.Text != expected[i] {
+			t.Errorf("entry %d: expected %q, got %q", i, expected[i], entry.Text)
+		}
+		if entry.EntityName != "Test Log" {
+			t.Errorf("entry %d: expected entity Test Log, got %q", i, entry.EntityName)
+		}
+	}
+
+	a.Clear()
+	goroutinesAfter := runtime.NumGoroutine()
+	for deadline := time.Now().Add(5 * time.Second); goroutinesAfter > goroutinesBefore && time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		goroutinesAfter = runtime.NumGoroutine()
+	}
+	if goroutinesAfter > goroutinesBefore {
+		buf := make([]byte, 1<<16)
+		t.Errorf("live update goroutines are still running after Clear: %d before, %d after\n%s", goroutinesBefore, goroutinesAfter, buf[:runtime.Stack(buf, true)])
+	}
+	if a.liveUpdate != nil {
+		t.Error("live update session is not reset by Clear")
+	}
+}
+
+func TestLiveUpdateSkipsRotatedLog(t *testing.T) {
+	folder := t.TempDir()
+	logFile := filepath.Join(folder, "test.log")
+	if err := os.WriteFile(logFile, []byte("2022-01-01 10:00:00,000 INFO first\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a := &Analyzer{FolderToWorkWith: folder}
+	a.AddDynamicEntity(newTestLogEntity())
+	a.ParseLogDirectory(folder)
+	a.GenerateFilters()
+	a.EnableLogsLiveUpdate()
+	defer a.Clear()
+	time.Sleep(time.Second)
+
+	// IntelliJ rotates idea.log to idea.1.log and starts a new idea.log
+	if err := os.Rename(logFile, filepath.Join(folder, "test.1.log")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(logFile, []byte("2022-01-01 10:00:01,000 INFO after rotation\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var logs Logs
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		logs = a.GetVisibleLogs()
+		if len(logs) == 2 {
+			break
+		}
+	}
+	// the folder watcher reports the rotated log after it stays unchanged for one poll
+	time.Sleep(3 * folderPollInterval)
+	logs = a.GetVisibleLogs()
+	if len(logs) != 2 || logs[0].Text != "2022-01-01 10:00:00,000 INFO first" || logs[1].Text != "2022-01-01 10:00:01,000 INFO after rotation" {
+		t.Errorf("expected entries of the tailed log only, got %v", logs)
+	}
+	if paths := a.getInstancePaths("Test Log"); len(paths) != 1 || paths[0] != logFile {
+		t.Errorf("rotated log should not be collected, instances: %v", paths)
+	}
+}
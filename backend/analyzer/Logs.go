@@ -19,6 +19,8 @@ type LogEntry struct {
 	Time             time.Time
 	Text             string
 	Visible          bool
+	SuspectPlugin    string //Plugin that caused the exception. See Analyzer.GetPluginErrors
+	HasTimeZone      bool   //Time is parsed together with its offset, so it does not depend on the source time zone
 }
 
 //ConvertToHTML Represents logs as HTML based on Logs.gohtml template
@@ -33,6 +35,11 @@ func (logs Logs) ConvertToHTML() string {
 	return tpl.String()
 }
 
+//DisplayTime returns the time of the entry in the time zone chosen for displaying
+func (l LogEntry) DisplayTime() time.Time {
+	return l.Time.In(getDisplayLocation())
+}
+
 func (l LogEntry) ConvertToHTML() string {
 	ls := Logs{l}
 	return ls.ConvertToHTML()
@@ -52,6 +59,18 @@ func (logs *Logs) AppendSeveral(entityName string, instanceProperties DynamicEnt
 
 }
 
+//Insert adds one log entry keeping logs sorted by time. The entry goes after entries of the same time. Returns the inserted entry.
+func (logs *Logs) Insert(entityName string, instanceProperties DynamicEntityProperties, entry LogEntry) LogEntry {
+	entry.EntityInstanceId = instanceProperties.Hash
+	entry.EntityName = entityName
+	entry.Visible = instanceProperties.Visible
+	i := sort.Search(len(*logs), func(i int) bool { return (*logs)[i].Time.After(entry.Time) })
+	*logs = append(*logs, LogEntry{})
+	copy((*logs)[i+1:], (*logs)[i:])
+	(*logs)[i] = entry
+	return entry
+}
+
 func (logs *Logs) IsEmpty() bool {
 	return reflect.ValueOf(*logs).IsZero()
 }
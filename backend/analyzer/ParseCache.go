@@ -0,0 +1,49 @@
+package analyzer
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+//parseCache keeps the result of parsing per file, so reports shown both as log entries and in their own tabs (GC pauses, crashes, indexing)
+//are parsed once. The file is parsed again if it was changed since. Caches are reset by Analyzer.Clear.
+type parseCache[T any] struct {
+	mutex sync.Mutex
+	files map[string]parsedFile[T]
+}
+
+type parsedFile[T any] struct {
+	modTime time.Time
+	size    int64
+	value   T
+}
+
+//get returns the cached result for the path or parses the file. The lock is not held while parsing, so different files are parsed in parallel.
+func (c *parseCache[T]) get(path string, parse func(path string) T) T {
+	var modTime time.Time
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		modTime, size = info.ModTime(), info.Size()
+	}
+	c.mutex.Lock()
+	cached, found := c.files[path]
+	c.mutex.Unlock()
+	if found && cached.modTime.Equal(modTime) && cached.size == size {
+		return cached.value
+	}
+	value := parse(path)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.files == nil {
+		c.files = make(map[string]parsedFile[T])
+	}
+	c.files[path] = parsedFile[T]{modTime: modTime, size: size, value: value}
+	return value
+}
+
+func (c *parseCache[T]) clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.files = nil
+}
@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"log"
+	"os"
+	"sync"
+)
+
+//ParsingSettings limit what is parsed and what is shown by default. Zero values mean no limit.
+type ParsingSettings struct {
+	HiddenEntities           []string // HiddenEntities are names of entities unchecked in filters by default, for example "Rider MsBuildTask"
+	MaxParsedFileSizeMB      int      // MaxParsedFileSizeMB is the size of files that are not parsed but listed in other files
+	MaxVisibleFileSizeMB     int      // MaxVisibleFileSizeMB is the size of files that are parsed but unchecked in filters by default
+	MaxVisibleRiderLogSizeMB int      // MaxVisibleRiderLogSizeMB is the same as MaxVisibleFileSizeMB for Rider backend logs, they are usually much bigger than other logs
+	MaxLogEntries            int      // MaxLogEntries is the number of the latest entries kept in memory, older entries are dropped
+	FollowNestedArchives     bool     // FollowNestedArchives extracts zip archives found in the analyzed folder and analyzes their content
+}
+
+//maxNestedArchivesDepth limits extraction of archives inside extracted archives
+const maxNestedArchivesDepth = 3
+
+var (
+	parsingSettings      = ParsingSettings{}
+	parsingSettingsMutex sync.RWMutex
+)
+
+//SetParsingSettings sets limits used for the logs analyzed next
+func SetParsingSettings(s ParsingSettings) {
+	parsingSettingsMutex.Lock()
+	defer parsingSettingsMutex.Unlock()
+	parsingSettings = s
+}
+
+//GetParsingSettings returns limits set with SetParsingSettings
+func GetParsingSettings() ParsingSettings {
+	parsingSettingsMutex.RLock()
+	defer parsingSettingsMutex.RUnlock()
+	return parsingSettings
+}
+
+//isTooBigToParse checks the size of the file, folders are always parsed
+func (s ParsingSettings) isTooBigToParse(path string) bool {
+	return s.MaxParsedFileSizeMB > 0 && isFileBiggerThanMB(path, s.MaxParsedFileSizeMB)
+}
+
+//isVisibleByDefault checks if the instance of the entity should be checked in filters by default
+func (s ParsingSettings) isVisibleByDefault(entityName string, path string) bool {
+	if SliceContains(s.HiddenEntities, entityName) != -1 {
+		return false
+	}
+	if s.MaxVisibleFileSizeMB > 0 && isFileBiggerThanMB(path, s.MaxVisibleFileSizeMB) {
+		log.Printf("File %s is bigger than %d MB, it is hidden by default", path, s.MaxVisibleFileSizeMB)
+		return false
+	}
+	return true
+}
+
+func isFileBiggerThanMB(path string, sizeMB int) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir() && info.Size() >= int64(sizeMB)*1024*1024
+}
+
+//enforceMaxLogEntries drops the oldest entries if there are more than MaxLogEntries. Logs are sorted by time.
+func (a *Analyzer) enforceMaxLogEntries() {
+	limit := GetParsingSettings().MaxLogEntries
+	writeSyncer.Lock()
+	defer writeSyncer.Unlock()
+	if limit <= 0 || len(a.AggregatedLogs) <= limit {
+		return
+	}
+	a.AggregatedLogs.SortByTime()
+	dropped := len(a.AggregatedLogs) - limit
+	a.AggregatedLogs = append(Logs{}, a.AggregatedLogs[dropped:]...)
+	log.Printf("Logs have more than %d entries, %d oldest entries are dropped", limit, dropped)
+}
+
+//parseNestedArchive extracts the archive found in the analyzed folder to a temp folder and analyzes it together with the analyzed folder
+func (a *Analyzer) parseNestedArchive(archive string, depth int) {
+	dest, err := os.MkdirTemp("", "IntelliJLogsAnalyzer-nested")
+	if err != nil {
+		log.Printf("Could not create temp folder for %s: %s", archive, err)
+		return
+	}
+	a.extractedArchives = append(a.extractedArchives, dest)
+	if err := ExtractZip(archive, dest); err != nil {
+		log.Printf("Could not extract nested archive %s: %s", archive, err)
+		return
+	}
+	log.Printf("Extracted nested archive %s to %s", archive, dest)
+	a.AdditionalFolders = append(a.AdditionalFolders, dest)
+	a.parseLogDirectory(dest, depth+1)
+}
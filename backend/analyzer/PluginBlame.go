@@ -0,0 +1,215 @@
+package analyzer
+
+import (
+	"bytes"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var (
+	pluginToBlameRegex = regexp.MustCompile(`Plugin to blame:\s*(?P<Plugin>.+?)\s+version:`)
+	stackFrameRegex    = regexp.MustCompile(`(?m)^\s*at\s+(?P<Class>[\w$.]+)\.[\w$<>]+\(`)
+	//platformPackages are never attributed to plugins
+	platformPackages = []string{"java.", "javax.", "jdk.", "sun.", "kotlin.", "kotlinx.", "com.intellij.", "org.jetbrains.", "com.jetbrains.", "groovy.", "org.apache.", "com.google."}
+)
+
+//pluginPackages maps package prefix (for example "com.example.myplugin") to the plugin name. Defined by user in the configuration directory.
+var pluginPackages = map[string]string{}
+
+func SetPluginPackages(packages map[string]string) {
+	pluginPackages = packages
+}
+
+//PluginErrorCount is the number of errors caused by the plugin
+type PluginErrorCount struct {
+	Plugin   string
+	IsCustom bool // IsCustom is true if the plugin is listed among custom plugins of static info
+	Errors   int
+	Last     time.Time
+}
+
+//PluginErrors is the list of plugins that caused errors, the most error-prone plugin goes first
+type PluginErrors []PluginErrorCount
+
+//GetPluginErrors tags exceptions with the plugin to blame and counts errors per plugin. Analyzes logs if it was not done already.
+//Plugin is taken from "Plugin to blame" line of the exception. If there is no such line, stack frames are matched against package prefixes
+//defined by user and the prefixes learnt from the exceptions that have "Plugin to blame" line.
+//Logs are tagged under lock, as live update inserts entries and shifts their indexes.
+func (a *Analyzer) GetPluginErrors() PluginErrors {
+	customPlugins := make(map[string]bool)
+	for _, info := range *a.GetStaticInfo() {
+		for _, plugin := range info.PluginsList {
+			customPlugins[strings.TrimSpace(plugin.Name)] = true
+		}
+	}
+	writeSyncer.Lock()
+	defer writeSyncer.Unlock()
+	if a.AggregatedPluginErrors != nil {
+		return a.AggregatedPluginErrors
+	}
+	blamed, blameLines := a.findBlamedExceptions()
+	packages := make(map[string]string)
+	for i, plugin := range blamed {
+		if prefix := firstPluginPackage(a.AggregatedLogs[i].Text); len(prefix) > 0 {
+			packages[prefix] = plugin
+		}
+	}
+	for prefix, plugin := range pluginPackages {
+		packages[prefix] = plugin
+	}
+	counts := make(map[string]*PluginErrorCount)
+	for i, entry := range a.AggregatedLogs {
+		if plugin, isBlameLine := blameLines[i]; isBlameLine {
+			a.AggregatedLogs[i].SuspectPlugin = plugin
+			continue
+		}
+		if !isErrorEntry(entry) {
+			continue
+		}
+		plugin, found := blamed[i]
+		if !found {
+			var prefix string
+			plugin, prefix = findPluginByFrames(entry.Text, packages)
+			// Learnt package prefixes may be too broad, so only plugins installed by user are suspected. Prefixes defined by user are trusted.
+			if _, isUserPrefix := pluginPackages[prefix]; !isUserPrefix && len(customPlugins) > 0 && !customPlugins[plugin] {
+				plugin = ""
+			}
+		}
+		if len(plugin) == 0 {
+			continue
+		}
+		a.AggregatedLogs[i].SuspectPlugin = plugin
+		if counts[plugin] == nil {
+			counts[plugin] = &PluginErrorCount{Plugin: plugin, IsCustom: customPlugins[plugin]}
+		}
+		counts[plugin].Errors++
+		if entry.Time.After(counts[plugin].Last) {
+			counts[plugin].Last = entry.Time
+		}
+	}
+	pluginErrors := PluginErrors{}
+	for _, count := range counts {
+		pluginErrors = append(pluginErrors, *count)
+	}
+	sort.Slice(pluginErrors, func(i, j int) bool {
+		if pluginErrors[i].Errors != pluginErrors[j].Errors {
+			return pluginErrors[i].Errors > pluginErrors[j].Errors
+		}
+		return pluginErrors[i].Plugin < pluginErrors[j].Plugin
+	})
+	a.AggregatedPluginErrors = pluginErrors
+	return pluginErrors
+}
+
+//findBlamedExceptions maps indexes of exceptions to the plugin from "Plugin to blame" line.
+//IDE writes this line as a separate entry next to the exception, such lines are returned as blameLines and are not counted as errors.
+func (a *Analyzer) findBlamedExceptions() (blamed map[int]string, blameLines map[int]string) {
+	blamed, blameLines = make(map[int]string), make(map[int]string)
+	logs := a.AggregatedLogs
+	for i, entry := range logs {
+		plugin := findPluginToBlame(entry.Text)
+		if len(plugin) == 0 {
+			continue
+		}
+		if stackFrameRegex.MatchString(entry.Text) {
+			blamed[i] = plugin
+			continue
+		}
+		// Entries of the same moment may be reordered by sorting, so the exception is looked for on both sides, the preceding one is preferred
+		for _, j := range []int{i - 1, i + 1, i - 2, i + 2, i - 3, i + 3} {
+			if j >= 0 && j < len(logs) && logs[j].EntityInstanceId == entry.EntityInstanceId && isErrorEntry(logs[j]) && stackFrameRegex.MatchString(logs[j].Text) {
+				blamed[j] = plugin
+				blameLines[i] = plugin
+				break
+			}
+		}
+		if _, found := blameLines[i]; !found {
+			blamed[i] = plugin
+		}
+	}
+	return blamed, blameLines
+}
+
+func isErrorEntry(entry LogEntry) bool {
+	switch entry.Severity {
+	case "ERROR", "SEVERE", "EXCPT":
+		return true
+	}
+	return strings.Contains(entry.Text, "Plugin to blame:")
+}
+
+func findPluginToBlame(text string) string {
+	if match := pluginToBlameRegex.FindStringSubmatch(text); match != nil {
+		return strings.TrimSpace(match[pluginToBlameRegex.SubexpIndex("Plugin")])
+	}
+	return ""
+}
+
+//firstPluginPackage returns the package (up to three segments, e.g. "com.example.plugin") of the topmost frame that does not belong to the platform
+func firstPluginPackage(text string) string {
+	for _, match := range stackFrameRegex.FindAllStringSubmatch(text, -1) {
+		class := match[stackFrameRegex.SubexpIndex("Class")]
+		if isPlatformClass(class) {
+			continue
+		}
+		segments := strings.Split(class, ".")
+		segments = segments[:len(segments)-1]
+		if len(segments) > 3 {
+			segments = segments[:3]
+		}
+		if len(segments) > 0 {
+			return strings.Join(segments, ".")
+		}
+	}
+	return ""
+}
+
+//findPluginByFrames returns the plugin of the topmost frame matching one of package prefixes together with the prefix. The longest prefix wins.
+func findPluginByFrames(text string, packages map[string]string) (string, string) {
+	for _, match := range stackFrameRegex.FindAllStringSubmatch(text, -1) {
+		class := match[stackFrameRegex.SubexpIndex("Class")]
+		plugin, longestPrefix := "", ""
+		for prefix, name := range packages {
+			if (class == prefix || strings.HasPrefix(class, prefix+".")) && len(prefix) > len(longestPrefix) {
+				plugin, longestPrefix = name, prefix
+			}
+		}
+		if len(plugin) > 0 {
+			return plugin, longestPrefix
+		}
+	}
+	return "", ""
+}
+
+func isPlatformClass(class string) bool {
+	for _, prefix := range platformPackages {
+		if strings.HasPrefix(class, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+//DisplayTime returns the time of the last error in the time zone chosen for displaying
+func (p PluginErrorCount) DisplayTime() time.Time {
+	return p.Last.In(getDisplayLocation())
+}
+
+//ConvertToHTML renders the number of errors per plugin based on PluginErrors.gohtml template
+func (pluginErrors PluginErrors) ConvertToHTML() string {
+	if len(pluginErrors) == 0 {
+		return ""
+	}
+	var tpl bytes.Buffer
+	t := template.Must(template.New("PluginErrors.gohtml").
+		ParseFS(tmplFS, "PluginErrors.gohtml"))
+	err := t.Execute(&tpl, pluginErrors)
+	if err != nil {
+		log.Printf("Template PluginErrors.gohtml execution failed. Error: %s", err.Error())
+	}
+	return tpl.String()
+}
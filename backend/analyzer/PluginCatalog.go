@@ -0,0 +1,162 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+const (
+	PluginStatusUnknown      = "unknown"
+	PluginStatusUpToDate     = "up-to-date"
+	PluginStatusOutdated     = "outdated"
+	PluginStatusIncompatible = "incompatible"
+)
+
+//CatalogPlugin is the plugin description exported from the marketplace
+type CatalogPlugin struct {
+	ID         int    `json:"id"`
+	XMLID      string `json:"xmlId"`
+	Name       string `json:"name"`
+	Version    string `json:"version"`    // Version is the latest released version
+	SinceBuild string `json:"sinceBuild"` // SinceBuild is the first compatible build of the latest version, for example "223"
+	UntilBuild string `json:"untilBuild"` // UntilBuild is the last compatible build of the latest version, for example "231.*". Empty if not limited
+}
+
+//PluginCatalog is an offline snapshot of the marketplace used to annotate plugins found in logs
+type PluginCatalog struct {
+	Plugins []CatalogPlugin
+	byName  map[string]CatalogPlugin
+}
+
+//pluginCatalog is used to annotate plugins while static info is aggregated. Nil if no snapshot is loaded.
+var pluginCatalog *PluginCatalog
+
+func SetPluginCatalog(catalog *PluginCatalog) {
+	pluginCatalog = catalog
+}
+
+//LoadPluginCatalog reads the snapshot, a JSON array of CatalogPlugin
+func LoadPluginCatalog(path string) (*PluginCatalog, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var plugins []CatalogPlugin
+	if err = json.Unmarshal(content, &plugins); err != nil {
+		return nil, err
+	}
+	return NewPluginCatalog(plugins), nil
+}
+
+func NewPluginCatalog(plugins []CatalogPlugin) *PluginCatalog {
+	c := &PluginCatalog{Plugins: plugins, byName: make(map[string]CatalogPlugin)}
+	for _, plugin := range plugins {
+		c.byName[strings.ToLower(plugin.Name)] = plugin
+		if len(plugin.XMLID) > 0 {
+			c.byName[strings.ToLower(plugin.XMLID)] = plugin
+		}
+	}
+	return c
+}
+
+//Find looks for the plugin by its name or XML id ignoring the case
+func (c *PluginCatalog) Find(name string) (plugin CatalogPlugin, found bool) {
+	if c == nil {
+		return plugin, false
+	}
+	plugin, found = c.byName[strings.ToLower(strings.TrimSpace(name))]
+	return plugin, found
+}
+
+//Annotate sets link, latest version and status of the plugin installed into IDE of the given build
+func (c *PluginCatalog) Annotate(plugin IDEPlugin, build string) IDEPlugin {
+	catalogPlugin, found := c.Find(plugin.Name)
+	if !found {
+		plugin.Status = PluginStatusUnknown
+		return plugin
+	}
+	if catalogPlugin.ID > 0 {
+		plugin.Link = "https://plugins.jetbrains.com/plugin/" + strconv.Itoa(catalogPlugin.ID)
+	}
+	plugin.LatestVersion = catalogPlugin.Version
+	plugin.CompatibleBuilds = catalogPlugin.SinceBuild + " — " + catalogPlugin.UntilBuild
+	buildNumber := buildNumberRegex.FindString(build)
+	switch {
+	case len(buildNumber) > 0 && !isBuildInRange(buildNumber, catalogPlugin.SinceBuild, catalogPlugin.UntilBuild):
+		plugin.Status = PluginStatusIncompatible
+	case compareVersions(plugin.Version, catalogPlugin.Version) < 0:
+		plugin.Status = PluginStatusOutdated
+	default:
+		plugin.Status = PluginStatusUpToDate
+	}
+	return plugin
+}
+
+//annotatePlugins annotates plugins of every source. Build of the source is used, if it is unknown - the build of the environment.
+func (a AggregatedStaticInfo) annotatePlugins(catalog *PluginCatalog) {
+	var environmentBuild string
+	for _, fact := range a.Merge() {
+		if fact.Name == "Build" {
+			environmentBuild = fact.Value()
+		}
+	}
+	for source, info := range a {
+		if len(info.PluginsList) == 0 {
+			continue
+		}
+		build := info.Build
+		if len(build) == 0 {
+			build = environmentBuild
+		}
+		plugins := make([]IDEPlugin, 0, len(info.PluginsList))
+		for _, plugin := range info.PluginsList {
+			plugins = append(plugins, catalog.Annotate(plugin, build))
+		}
+		info.PluginsList = plugins
+		a[source] = info
+	}
+}
+
+//isBuildInRange checks since-until range of the plugin. "*" in the bound matches any number.
+func isBuildInRange(build string, since string, until string) bool {
+	if len(since) > 0 && compareVersions(build, since) < 0 {
+		return false
+	}
+	if len(until) > 0 && compareVersions(build, until) > 0 {
+		return false
+	}
+	return true
+}
+
+//compareVersions compares numeric parts of versions and builds: 1.10 > 1.9, 223.8214 < 231.*
+func compareVersions(a string, b string) int {
+	aParts, bParts := versionParts(a), versionParts(b)
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		if aParts[i] == "*" || bParts[i] == "*" {
+			return 0
+		}
+		aNumber, aErr := strconv.Atoi(aParts[i])
+		bNumber, bErr := strconv.Atoi(bParts[i])
+		if aErr != nil || bErr != nil {
+			if c := strings.Compare(aParts[i], bParts[i]); c != 0 {
+				return c
+			}
+			continue
+		}
+		if aNumber != bNumber {
+			if aNumber < bNumber {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(aParts) - len(bParts)
+}
+
+func versionParts(version string) []string {
+	return strings.FieldsFunc(strings.TrimSpace(version), func(r rune) bool {
+		return r == '.' || r == '-' || r == '+' || r == ' '
+	})
+}
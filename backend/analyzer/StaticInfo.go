@@ -2,47 +2,148 @@ package analyzer
 
 import (
 	"bytes"
+	"encoding/json"
 	"log"
+	"sort"
 	"text/template"
+	"time"
 )
 
 type StaticInfo struct {
-	IDE         string
-	Build       string
-	JRE         string
-	OS          string
-	PluginsList []IDEPlugin
+	IDE                    string
+	Build                  string
+	JRE                    string
+	OS                     string
+	TimeZone               string
+	Runtime                string
+	Heap                   string
+	Cores                  string
+	RegistryOverrides      []string
+	DisabledBundledPlugins []string
+	PluginsList            []IDEPlugin
+	JVMSettings            JVMSettings
+	Started                time.Time     // Started is the time of the latest IDE start found in the source, its facts are preferred during merge
+	Sections               []InfoSection // Sections contain everything else found in the source, for example "Project", "VCS", "Encoding" or "Paths" sections of troubleshooting.txt
+}
+
+//InfoSection is a named group of properties, such as "=== Paths ===" section of troubleshooting.txt
+type InfoSection struct {
+	Name       string
+	Time       time.Time // Time is set for sections describing IDE sessions
+	Properties []InfoProperty
+}
+
+//InfoProperty is a "Name: Value" line. Values contains nested list items, for example registry keys listed under "Registry:"
+type InfoProperty struct {
+	Name   string
+	Value  string
+	Values []string
 }
 type IDEPlugin struct {
-	Version string
-	Name    string
-	Link    string
+	Version          string
+	Name             string
+	Link             string
+	Status           string // Status is one of PluginStatus* constants. Empty if no plugin catalog is loaded
+	LatestVersion    string
+	CompatibleBuilds string
 }
 
 //AggregatedStaticInfo is a source (such as troubleshooting.txt or idea.log) mapped to collected info
 type AggregatedStaticInfo map[string]StaticInfo
 
+//staticInfoView is the environment combined from all the sources followed by details specific to every source
+type staticInfoView struct {
+	Environment EnvironmentSummary
+	Sources     AggregatedStaticInfo
+}
+
 //ConvertToHTML Represents logs as HTML based on Logs.gohtml template
 func (a AggregatedStaticInfo) ConvertToHTML() string {
-	//todo: combine static info from several sources
 	if a.IsEmpty() {
 		return ""
 	}
 	var tpl bytes.Buffer
 	t := template.Must(template.New("StaticInfo.gohtml").
 		ParseFS(tmplFS, "StaticInfo.gohtml"))
-	err := t.Execute(&tpl, a)
+	err := t.Execute(&tpl, staticInfoView{Environment: a.Merge(), Sources: a})
 	if err != nil {
 		log.Printf("Template StaticInfo.gohtml parsing failed. Error: %s", err.Error())
 	}
 	return tpl.String()
 }
 
+//merge fills empty fields of the StaticInfo with values collected from another file of the same entity
+func (s *StaticInfo) merge(other StaticInfo) {
+	if other.Started.After(s.Started) {
+		*s, other = other, *s
+	}
+	if len(s.IDE) == 0 {
+		s.IDE = other.IDE
+	}
+	if len(s.Build) == 0 {
+		s.Build = other.Build
+	}
+	if len(s.JRE) == 0 {
+		s.JRE = other.JRE
+	}
+	if len(s.OS) == 0 {
+		s.OS = other.OS
+	}
+	if len(s.TimeZone) == 0 {
+		s.TimeZone = other.TimeZone
+	}
+	if len(s.Runtime) == 0 {
+		s.Runtime = other.Runtime
+	}
+	if len(s.Heap) == 0 {
+		s.Heap = other.Heap
+	}
+	if len(s.Cores) == 0 {
+		s.Cores = other.Cores
+	}
+	if len(s.RegistryOverrides) == 0 {
+		s.RegistryOverrides = other.RegistryOverrides
+	}
+	if len(s.DisabledBundledPlugins) == 0 {
+		s.DisabledBundledPlugins = other.DisabledBundledPlugins
+	}
+	s.Sections = append(s.Sections, other.Sections...)
+	sort.SliceStable(s.Sections, func(i, j int) bool { return s.Sections[i].Time.Before(s.Sections[j].Time) })
+	if len(s.PluginsList) == 0 {
+		s.PluginsList = other.PluginsList
+	}
+	s.JVMSettings.merge(other.JVMSettings)
+}
+
+//ConvertToJSON represents collected static info for exporting
+func (a AggregatedStaticInfo) ConvertToJSON() string {
+	content, err := json.MarshalIndent(staticInfoView{Environment: a.Merge(), Sources: a}, "", "  ")
+	if err != nil {
+		log.Printf("Could not convert static info to JSON. Error: %s", err)
+		return ""
+	}
+	return string(content)
+}
+
 func (a *AggregatedStaticInfo) IsEmpty() bool {
 	for _, info := range *a {
-		if len(info.IDE) > 0 || len(info.Build) > 0 || len(info.JRE) > 0 {
+		if len(info.IDE) > 0 || len(info.Build) > 0 || len(info.JRE) > 0 || len(info.TimeZone) > 0 || info.HasDetails() {
 			return false
 		}
 	}
 	return true
 }
+
+//HasDetails returns true if the source has information that is not a part of EnvironmentSummary
+func (s StaticInfo) HasDetails() bool {
+	if len(s.RegistryOverrides) > 0 || len(s.DisabledBundledPlugins) > 0 || len(s.PluginsList) > 0 || !s.JVMSettings.IsEmpty() {
+		return true
+	}
+	// "About" section is represented by EnvironmentSummary
+	for _, section := range s.Sections {
+		if section.Name != "About" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package analyzer
+
+import (
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SourceTimeZoneSetting and LocalTimeZoneSetting are special values of the display time zone setting.
+// Any other value is treated as IANA time zone name (for example "Europe/Amsterdam" or "UTC").
+const (
+	SourceTimeZoneSetting = "source"
+	LocalTimeZoneSetting  = "local"
+)
+
+// displayLocation is the time zone all log entries are rendered in. It is changed in settings while entries are rendered, read it with getDisplayLocation.
+var (
+	displayLocation      = time.UTC
+	displayLocationMutex sync.RWMutex
+)
+
+//SetDisplayLocation sets the time zone that is used to render log entries
+func SetDisplayLocation(loc *time.Location) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	displayLocationMutex.Lock()
+	defer displayLocationMutex.Unlock()
+	displayLocation = loc
+}
+
+func getDisplayLocation() *time.Location {
+	displayLocationMutex.RLock()
+	defer displayLocationMutex.RUnlock()
+	return displayLocation
+}
+
+//LoadTimeZone returns location by its name. "local" returns the location of the machine analyzer runs on.
+func LoadTimeZone(name string) (*time.Location, error) {
+	if strings.ToLower(name) == LocalTimeZoneSetting {
+		return time.Local, nil
+	}
+	return time.LoadLocation(name)
+}
+
+//Time zone is written as JVM option in idea.log and as "Time zone" property in troubleshooting.txt
+var (
+	jvmTimeZoneRegex      = regexp.MustCompile(`user\.timezone=([\w/+\-]+)`)
+	propertyTimeZoneRegex = regexp.MustCompile(`(?i)^\s*time\s?zone\s*:\s*([\w/+\-]+)`)
+)
+
+//FindTimeZone looks for the time zone name in a string of troubleshooting.txt or idea.log. For example "-Duser.timezone=Europe/Berlin" or "Time zone: Europe/Berlin"
+func FindTimeZone(s string) string {
+	tz := ""
+	if match := jvmTimeZoneRegex.FindStringSubmatch(s); match != nil {
+		tz = match[1]
+	} else if match := propertyTimeZoneRegex.FindStringSubmatch(s); match != nil {
+		tz = match[1]
+	}
+	if len(tz) > 0 {
+		if _, err := time.LoadLocation(tz); err != nil {
+			log.Printf("Found time zone '%s' but could not load it: %s", tz, err)
+			return ""
+		}
+	}
+	return tz
+}
+
+//sourceTimeZoneMutex guards Analyzer.SourceTimeZone, it is read by live update while user may override it
+var sourceTimeZoneMutex sync.RWMutex
+
+//GetSourceTimeZone returns the time zone logs of current bundle were written in
+func (a *Analyzer) GetSourceTimeZone() *time.Location {
+	sourceTimeZoneMutex.RLock()
+	defer sourceTimeZoneMutex.RUnlock()
+	if a.SourceTimeZone == nil {
+		return time.UTC
+	}
+	return a.SourceTimeZone
+}
+
+//DetectSourceTimeZone returns the first time zone found by static entities. UTC is used if nothing was found.
+func (a *Analyzer) DetectSourceTimeZone() (loc *time.Location, source string) {
+	for _, entity := range a.StaticEntities {
+		if tz := entity.CollectedInfo.TimeZone; len(tz) > 0 {
+			if loc, err := time.LoadLocation(tz); err == nil {
+				return loc, entity.Name
+			}
+		}
+	}
+	return time.UTC, ""
+}
+
+//SetSourceTimeZone reinterprets timestamps of all the collected log entries as wall clock of the given location.
+//Entities parse timestamps without zone as UTC, so the first call converts them from UTC to loc.
+//GC pauses, crashes, indexings and plugin errors are aggregated again with the new time zone.
+func (a *Analyzer) SetSourceTimeZone(loc *time.Location) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	writeSyncer.Lock()
+	defer writeSyncer.Unlock()
+	sourceTimeZoneMutex.Lock()
+	previous := a.SourceTimeZone
+	if previous == nil {
+		previous = time.UTC
+	}
+	a.SourceTimeZone = loc
+	sourceTimeZoneMutex.Unlock()
+	for i, entry := range a.AggregatedLogs {
+		if !entry.HasTimeZone {
+			a.AggregatedLogs[i].Time = changeTimeZone(entry.Time, previous, loc)
+		}
+	}
+	a.AggregatedLogs.SortByTime()
+	a.AggregatedGCEvents = nil
+	a.AggregatedCrashReports = nil
+	a.AggregatedIndexingHistory = nil
+	a.AggregatedPluginErrors = nil
+	log.Printf("Source time zone of logs is set to %s", loc)
+}
+
+//normalizeTime converts timestamp parsed without zone to the instant in the source time zone
+func (a *Analyzer) normalizeTime(t time.Time) time.Time {
+	return changeTimeZone(t, time.UTC, a.GetSourceTimeZone())
+}
+
+//normalizeEntryTime converts time of the entry with normalizeTime unless the entry has its own offset
+func (a *Analyzer) normalizeEntryTime(entry *LogEntry) {
+	if !entry.HasTimeZone {
+		entry.Time = a.normalizeTime(entry.Time)
+	}
+}
+
+//changeTimeZone keeps the wall clock of t (as it is seen in "from" location) and moves it to "to" location
+func changeTimeZone(t time.Time, from *time.Location, to *time.Location) time.Time {
+	if t.IsZero() || from == to {
+		return t
+	}
+	w := t.In(from)
+	return time.Date(w.Year(), w.Month(), w.Day(), w.Hour(), w.Minute(), w.Second(), w.Nanosecond(), to).UTC()
+}
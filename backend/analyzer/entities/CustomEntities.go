@@ -0,0 +1,188 @@
+package entities
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"log_analyzer/backend/analyzer"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+//CustomEntityDefinition describes a log type declared by user in a config file, so it can be parsed without rebuilding the analyzer.
+type CustomEntityDefinition struct {
+	Name                  string `json:"Name"`
+	PathGlob              string `json:"PathGlob"`              // PathGlob is matched against the file name, for example "my-plugin*.log"
+	PathRegex             string `json:"PathRegex"`             // PathRegex is matched against the full path (with "/" separators)
+	IgnoredPathRegex      string `json:"IgnoredPathRegex"`      // IgnoredPathRegex marks files that should neither be parsed nor listed in other files
+	LineRegex             string `json:"LineRegex"`             // LineRegex describes the first line of the entry. Supported named groups: Year, Month, Day, Hours, Minutes, Seconds, MiliSeconds, Severity, Class, Body
+	ContinuationRegex     string `json:"ContinuationRegex"`     // ContinuationRegex (if defined) limits lines appended to the previous entry. By default, every line that does not match LineRegex is appended.
+	LineHighlightingColor string `json:"LineHighlightingColor"` // LineHighlightingColor is a CSS color, for example "#a0c4ff"
+	LiveUpdate            bool   `json:"LiveUpdate"`            // LiveUpdate allows tailing of the file when live update is enabled
+	DefaultVisible        *bool  `json:"DefaultVisible"`        // DefaultVisible is true if not defined
+}
+
+type customEntity struct {
+	CustomEntityDefinition
+	pathRegex         *regexp.Regexp
+	ignoredPathRegex  *regexp.Regexp
+	lineRegex         *regexp.Regexp
+	continuationRegex *regexp.Regexp
+}
+
+//NewCustomEntity validates the definition and converts it to the DynamicEntity
+func NewCustomEntity(d CustomEntityDefinition) (entity analyzer.DynamicEntity, err error) {
+	c := customEntity{CustomEntityDefinition: d}
+	if len(strings.TrimSpace(d.Name)) == 0 {
+		return entity, errors.New("entity name is not defined")
+	}
+	if len(d.PathGlob) == 0 && len(d.PathRegex) == 0 {
+		return entity, fmt.Errorf("entity \"%s\" defines neither PathGlob nor PathRegex", d.Name)
+	}
+	if len(d.PathGlob) > 0 {
+		if _, err := filepath.Match(d.PathGlob, ""); err != nil {
+			return entity, fmt.Errorf("entity \"%s\" has malformed PathGlob: %w", d.Name, err)
+		}
+	}
+	if c.pathRegex, err = compileOptionalRegex(d.PathRegex); err != nil {
+		return entity, fmt.Errorf("entity \"%s\" has malformed PathRegex: %w", d.Name, err)
+	}
+	if c.ignoredPathRegex, err = compileOptionalRegex(d.IgnoredPathRegex); err != nil {
+		return entity, fmt.Errorf("entity \"%s\" has malformed IgnoredPathRegex: %w", d.Name, err)
+	}
+	if c.continuationRegex, err = compileOptionalRegex(d.ContinuationRegex); err != nil {
+		return entity, fmt.Errorf("entity \"%s\" has malformed ContinuationRegex: %w", d.Name, err)
+	}
+	if len(d.LineRegex) == 0 {
+		return entity, fmt.Errorf("entity \"%s\" does not define LineRegex", d.Name)
+	}
+	if c.lineRegex, err = regexp.Compile(d.LineRegex); err != nil {
+		return entity, fmt.Errorf("entity \"%s\" has malformed LineRegex: %w", d.Name, err)
+	}
+	for _, group := range []string{"Hours", "Minutes", "Seconds"} {
+		if c.lineRegex.SubexpIndex(group) == -1 {
+			return entity, fmt.Errorf("entity \"%s\" LineRegex does not contain named group \"%s\"", d.Name, group)
+		}
+	}
+	entity = analyzer.DynamicEntity{
+		Name:                  d.Name,
+		ConvertPathToLogs:     c.parseFile,
+		CheckPath:             c.checkPath,
+		GetDisplayName:        getDisplayName,
+		LineHighlightingColor: d.LineHighlightingColor,
+	}
+	if c.ignoredPathRegex != nil {
+		entity.CheckIgnoredPath = c.ignoredPathRegex.MatchString
+	}
+	if d.DefaultVisible != nil {
+		visible := *d.DefaultVisible
+		entity.DefaultVisibility = func(path string) bool {
+			return visible
+		}
+	}
+	if d.LiveUpdate {
+		entity.GetChangeablePath = func(path string) string {
+			return path
+		}
+		entity.ConvertStringToLogs = c.parseString
+	}
+	return entity, nil
+}
+
+func compileOptionalRegex(expr string) (*regexp.Regexp, error) {
+	if len(expr) == 0 {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
+
+func (c *customEntity) checkPath(path string) bool {
+	if len(c.PathGlob) > 0 {
+		if matched, _ := filepath.Match(c.PathGlob, filepath.Base(path)); matched {
+			return isRegularFile(path)
+		}
+	}
+	if c.pathRegex != nil && c.pathRegex.MatchString(filepath.ToSlash(path)) {
+		return isRegularFile(path)
+	}
+	return false
+}
+
+func isRegularFile(path string) bool {
+	fileInfo, err := os.Stat(path)
+	return err == nil && fileInfo.Mode().IsRegular()
+}
+
+func (c *customEntity) parseFile(path string) analyzer.Logs {
+	reader, err := os.Open(path)
+	if err != nil {
+		log.Printf("Could not open %s for entity \"%s\": %s", path, c.Name, err)
+		return nil
+	}
+	defer reader.Close()
+	fileDate := analyzer.GetFileModTime(path)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	logs := []analyzer.LogEntry{}
+	for scanner.Scan() {
+		currentString := scanner.Text()
+		if entry, err := c.parseLine(currentString, fileDate); err == nil {
+			logs = append(logs, entry)
+		} else if len(logs) > 0 && c.isContinuation(currentString) {
+			logs[len(logs)-1].Text = logs[len(logs)-1].Text + "\n" + currentString
+		}
+	}
+	return logs
+}
+
+//parseString converts the entry (first line and its continuation lines) received during live update
+func (c *customEntity) parseString(s string) (analyzer.LogEntry, error) {
+	lines := strings.Split(s, "\n")
+	entry, err := c.parseLine(lines[0], time.Now())
+	if err != nil {
+		return entry, err
+	}
+	for _, line := range lines[1:] {
+		if c.isContinuation(line) {
+			entry.Text = entry.Text + "\n" + line
+		}
+	}
+	return entry, nil
+}
+
+func (c *customEntity) isContinuation(line string) bool {
+	return c.continuationRegex == nil || c.continuationRegex.MatchString(line)
+}
+
+//parseLine converts a line to the log entry. Date parts missing in LineRegex are taken from fileDate.
+func (c *customEntity) parseLine(line string, fileDate time.Time) (entry analyzer.LogEntry, err error) {
+	match := c.lineRegex.FindStringSubmatch(line)
+	if match == nil {
+		return analyzer.LogEntry{Text: line}, errors.New("line does not match \"" + c.Name + "\" format: " + line)
+	}
+	group := func(name string, fallback string) string {
+		if i := c.lineRegex.SubexpIndex(name); i > 0 && len(match[i]) > 0 {
+			return match[i]
+		}
+		return fallback
+	}
+	s := fmt.Sprintf("%s-%s-%sT%s:%s:%s.%sZ",
+		group("Year", fileDate.Format("2006")), group("Month", fileDate.Format("01")), group("Day", fileDate.Format("02")),
+		group("Hours", "00"), group("Minutes", "00"), group("Seconds", "00"), group("MiliSeconds", "000"))
+	entry.Time, err = time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return analyzer.LogEntry{Text: line}, fmt.Errorf("could not parse time of \"%s\" entry: %w", c.Name, err)
+	}
+	entry.Severity = strings.ToUpper(strings.TrimSpace(group("Severity", "INFO")))
+	body := group("Body", line)
+	if class := strings.TrimSpace(group("Class", "")); len(class) > 0 {
+		entry.Text = class + " — " + strings.TrimSpace(body)
+	} else {
+		entry.Text = body
+	}
+	return entry, nil
+}
@@ -18,8 +18,8 @@ func init() {
 }
 func isThreadDump(path string) bool {
 	if strings.Contains(path, "threadDump") {
-		fileInfo, _ := os.Stat(path)
-		if fileInfo.IsDir() {
+		// The folder may be removed while live update collects it
+		if fileInfo, err := os.Stat(path); err == nil && fileInfo.IsDir() {
 			return true
 		}
 	}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"log_analyzer/backend/analyzer"
 	"os"
+	"regexp"
 	"strings"
 )
 
@@ -24,8 +25,14 @@ func isTroubleshootingInfo(path string) bool {
 }
 
 func parseTroubleshootingInfo(path string) (a analyzer.StaticInfo) {
-	reader, _ := os.Open(path)
+	reader, err := os.Open(path)
+	if err != nil {
+		log.Printf("parseTroubleshootingInfo failed. ERROR: %s", err)
+		return a
+	}
+	defer reader.Close()
 	bufReader := bufio.NewReader(reader)
+	var lines []string
 	for {
 		currentString, err := bufReader.ReadString('\n')
 		var build string
@@ -41,6 +48,10 @@ func parseTroubleshootingInfo(path string) (a analyzer.StaticInfo) {
 		if os := findOS(currentString); len(os) > 0 {
 			a.OS = os
 		}
+		if tz := analyzer.FindTimeZone(currentString); len(tz) > 0 && len(a.TimeZone) == 0 {
+			a.TimeZone = tz
+		}
+		lines = append(lines, strings.TrimRight(currentString, "\r\n"))
 		if err == io.EOF {
 			break
 		}
@@ -48,11 +59,108 @@ func parseTroubleshootingInfo(path string) (a analyzer.StaticInfo) {
 			log.Printf("parseTroubleshootingInfo failed. ERROR: %s", err)
 		}
 	}
+	a.Sections = parseTroubleshootingSections(lines)
+	fillEnvironmentFromSections(&a)
 	return a
 }
 
+//parseTroubleshootingSections splits troubleshooting.txt by "=== Section ===" headers.
+//"Key: Value" lines become properties, indented lines under "Key:" become list items of that property.
+func parseTroubleshootingSections(lines []string) (sections []analyzer.InfoSection) {
+	sectionHeader := regexp.MustCompile(`^\s*=+\s*(.*?)\s*=+\s*$`)
+	property := regexp.MustCompile(`^(?P<Name>[^:\s][^:]*?)\s*:\s*(?P<Value>.*)$`)
+	current := analyzer.InfoSection{Name: "About"}
+	for _, line := range lines {
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		if header := sectionHeader.FindStringSubmatch(line); header != nil {
+			if len(current.Properties) > 0 {
+				sections = append(sections, current)
+			}
+			current = analyzer.InfoSection{Name: header[1]}
+			continue
+		}
+		last := len(current.Properties) - 1
+		isIndented := line[0] == ' ' || line[0] == '\t'
+		if isIndented && last >= 0 && len(current.Properties[last].Value) == 0 {
+			current.Properties[last].Values = append(current.Properties[last].Values, strings.TrimSpace(line))
+			continue
+		}
+		if match := property.FindStringSubmatch(line); match != nil && !strings.Contains(match[1], "//") {
+			current.Properties = append(current.Properties, analyzer.InfoProperty{
+				Name:  strings.TrimSpace(match[1]),
+				Value: strings.TrimSpace(match[2]),
+			})
+		} else {
+			current.Properties = append(current.Properties, analyzer.InfoProperty{Value: strings.TrimSpace(line)})
+		}
+	}
+	if len(current.Properties) > 0 {
+		sections = append(sections, current)
+	}
+	return sections
+}
+
+//fillEnvironmentFromSections picks well-known facts (product, runtime, heap, registry, disabled plugins) from parsed sections
+func fillEnvironmentFromSections(a *analyzer.StaticInfo) {
+	for _, section := range a.Sections {
+		for _, p := range section.Properties {
+			name := strings.ToLower(p.Name)
+			switch {
+			case len(name) == 0 && len(a.IDE) == 0 && section.Name == "About":
+				a.IDE = p.Value
+			case name == "product" || name == "ide":
+				a.IDE = p.Value
+			case name == "runtime version" || name == "runtime":
+				a.Runtime = p.Value
+			case name == "memory" || name == "heap memory" || name == "max heap size":
+				a.Heap = p.Value
+			case name == "cores":
+				a.Cores = p.Value
+			case name == "registry":
+				a.RegistryOverrides = append(a.RegistryOverrides, listValues(p)...)
+			case strings.Contains(name, "disabled") && strings.Contains(name, "plugins"):
+				a.DisabledBundledPlugins = append(a.DisabledBundledPlugins, listValues(p)...)
+			}
+		}
+		if strings.EqualFold(section.Name, "Registry") {
+			for _, p := range section.Properties {
+				if len(p.Name) == 0 {
+					a.RegistryOverrides = append(a.RegistryOverrides, p.Value)
+				} else {
+					a.RegistryOverrides = append(a.RegistryOverrides, p.Name+"="+p.Value)
+				}
+			}
+		}
+	}
+}
+
+//listValues returns nested items of the property or splits its value like "[a, b, c]"
+func listValues(p analyzer.InfoProperty) []string {
+	if len(p.Values) > 0 {
+		return p.Values
+	}
+	value := strings.Trim(p.Value, "[] ")
+	if len(value) == 0 {
+		return nil
+	}
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); len(v) > 0 {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 func findCustomPlugins(currentString string) (pluginsList []analyzer.IDEPlugin) {
 	s := analyzer.GetRegexNamedCapturedGroups(`^Custom plugins: \[(?P<PluginsString>.*)\]`, currentString)["PluginsString"]
+	return parsePluginsList(s)
+}
+
+//parsePluginsList converts "Plugin A (1.0), Plugin B (2.1)" to the list of plugins
+func parsePluginsList(s string) (pluginsList []analyzer.IDEPlugin) {
 	if len(s) == 0 {
 		return nil
 	}
@@ -61,7 +169,7 @@ func findCustomPlugins(currentString string) (pluginsList []analyzer.IDEPlugin)
 		s := analyzer.GetRegexNamedCapturedGroups(`^\s*(?P<Plugin>.*)\s+\((?P<Version>.*)\)$`, pluginAsString)
 		version := s["Version"]
 		name := s["Plugin"]
-		//todo: retreive plugin's link
+		//The link is replaced with the plugin page if the plugin is found in the catalog
 		pluginsList = append(pluginsList, analyzer.IDEPlugin{
 			Version: version,
 			Name:    name,
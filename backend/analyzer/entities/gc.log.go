@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"log_analyzer/backend/analyzer"
+	"path/filepath"
+	"regexp"
+)
+
+func init() {
+	CurrentAnalyzer.AddDynamicEntity(analyzer.DynamicEntity{
+		Name:                  "GC Log",
+		ConvertPathToLogs:     parseGCLogFile,
+		CheckPath:             isGCLog,
+		GetDisplayName:        getDisplayName,
+		LineHighlightingColor: "#c39bd3",
+	})
+}
+
+//gcLogNameRegex matches files written by -Xlog:gc*:file=gc.log, including rotated gc.log.0, gc.log.1, etc
+var gcLogNameRegex = regexp.MustCompile(`^gc[\w\-.]*\.log(\.\d+)?$`)
+
+func isGCLog(path string) bool {
+	return gcLogNameRegex.MatchString(filepath.Base(path))
+}
+
+func parseGCLogFile(path string) analyzer.Logs {
+	return analyzer.ParseGCLog(path).ConvertToLogs()
+}
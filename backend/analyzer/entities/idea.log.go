@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"log"
 	"log_analyzer/backend/analyzer"
 	"os"
 	"path/filepath"
@@ -21,6 +22,11 @@ func init() {
 		GetChangeablePath:   getIdeaLogChangeablePath,
 		ConvertStringToLogs: parseIdeaLogString,
 	})
+	CurrentAnalyzer.AddStaticEntity(analyzer.StaticEntity{
+		Name:                "idea.log",
+		ConvertToStaticInfo: parseIdeaLogStaticInfo,
+		CheckPath:           isIdeaLog,
+	})
 }
 func isIdeaLog(path string) bool {
 	logMatcher := regexp.MustCompile(`idea\.\d+.log`)
@@ -92,6 +98,103 @@ func parseIdeaLogString(logEntryAsString string) (currentEntry analyzer.LogEntry
 	return currentEntry, err
 }
 
+var (
+	//ideaLogBannerProperties are written by IDE to idea.log right after "IDE STARTED" line
+	ideaLogBannerProperties = []string{"IDE", "OS", "JRE", "JVM", "PID", "JVM options", "CPU cores", "Loaded custom plugins"}
+	ideaLogIDERegex         = regexp.MustCompile(`^(?P<IDE>.*?)\s*\(build\s+(?P<Build>#[^,)]+)`)
+)
+
+//ideaLogBannerMaxLines is the number of lines after the start of the banner the rest of its properties are looked for.
+//Some properties (for example "Loaded custom plugins") are written after the plugins are loaded, not right after "IDE STARTED" line.
+const ideaLogBannerMaxLines = 1000
+
+//parseIdeaLogStaticInfo collects environment information written by IDE to idea.log on startup.
+//Every startup banner of the file gets its own section, the rest of the info is taken from the latest session.
+func parseIdeaLogStaticInfo(path string) (a analyzer.StaticInfo) {
+	reader, err := os.Open(path)
+	if err != nil {
+		log.Printf("parseIdeaLogStaticInfo failed. path: %s, error: %s", path, err)
+		return a
+	}
+	defer reader.Close()
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var found map[string]bool
+	linesAfterStart := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		linesAfterStart++
+		if tz := analyzer.FindTimeZone(line); len(tz) > 0 {
+			a.TimeZone = tz
+		}
+		name, isBanner := findIdeaLogBannerProperty(line)
+		if !isBanner {
+			continue
+		}
+		entry, err := parseIdeaLogString(line)
+		if err != nil {
+			continue
+		}
+		if len(name) == 0 || len(a.Sections) == 0 {
+			// A new session starts. Banner of the rotated log may have no "IDE STARTED" line.
+			a = analyzer.StaticInfo{TimeZone: a.TimeZone, Sections: a.Sections, Started: entry.Time}
+			a.Sections = append(a.Sections, analyzer.InfoSection{Name: "IDE started " + entry.Time.Format("02 Jan 2006 15:04:05"), Time: entry.Time})
+			found = make(map[string]bool)
+			linesAfterStart = 0
+			if len(name) == 0 {
+				continue
+			}
+		}
+		if found[name] || linesAfterStart > ideaLogBannerMaxLines {
+			continue
+		}
+		found[name] = true
+		message := strings.TrimSpace(entry.Text[strings.Index(entry.Text, "—")+len("—"):])
+		value := strings.TrimSpace(strings.TrimPrefix(message, name+":"))
+		property := analyzer.InfoProperty{Name: name, Value: value}
+		switch name {
+		case "IDE":
+			if match := ideaLogIDERegex.FindStringSubmatch(value); match != nil {
+				a.IDE = match[ideaLogIDERegex.SubexpIndex("IDE")]
+				a.Build = match[ideaLogIDERegex.SubexpIndex("Build")]
+			} else {
+				a.IDE = value
+			}
+		case "OS":
+			a.OS = value
+		case "JRE":
+			a.JRE = value
+		case "JVM":
+			a.Runtime = value
+		case "CPU cores":
+			a.Cores = strings.TrimSpace(strings.Split(value, ";")[0])
+		case "JVM options":
+			options := strings.Split(strings.Trim(value, "[]"), ", ")
+			a.JVMSettings.Xmx = analyzer.ParseVMOptions(options).Xmx
+			property = analyzer.InfoProperty{Name: name, Values: options}
+		case "Loaded custom plugins":
+			a.PluginsList = parsePluginsList(value)
+			property = analyzer.InfoProperty{Name: name, Values: listValues(property)}
+		}
+		session := &a.Sections[len(a.Sections)-1]
+		session.Properties = append(session.Properties, property)
+	}
+	return a
+}
+
+//findIdeaLogBannerProperty returns the name of startup banner property written in the line. Empty name is returned for "IDE STARTED" line.
+func findIdeaLogBannerProperty(line string) (name string, found bool) {
+	if strings.Contains(line, "- IDE STARTED -") {
+		return "", true
+	}
+	for _, name := range ideaLogBannerProperties {
+		if strings.Contains(line, " - "+name+": ") {
+			return name, true
+		}
+	}
+	return "", false
+}
+
 func getIdeaLogChangeablePath(path string) string {
 	if strings.HasSuffix(path, "idea.log") {
 		return path
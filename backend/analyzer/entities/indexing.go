@@ -1,7 +1,6 @@
 package entities
 
 import (
-	"fmt"
 	"log"
 	"log_analyzer/backend/analyzer"
 	"path/filepath"
@@ -27,26 +26,39 @@ func isIndexingFolder(path string) bool {
 }
 
 func parseIndexingDiagnosticFolder(path string) (l analyzer.Logs) {
+	if analyzer.IsIndexingEventsFile(path) {
+		l, err := analyzer.ParseIndexingEvents(path, getIndexingHistoryOfFolder(filepath.Dir(path)))
+		if err != nil {
+			log.Printf("Could not parse indexing events %s: %s", path, err)
+		}
+		return l
+	}
 	if isIndexingFile(path) {
-		l = append(l, analyzer.LogEntry{
+		entry := analyzer.LogEntry{
 			Severity: "INDEX",
 			Time:     getTimeStampFromIndexingFile(path),
 			Text:     "Indexing project: " + getIndexingProjectName(path) + " (show report.html). Report: " + filepath.Base(path),
-		})
+		}
+		if stats, err := analyzer.ParseIndexingDiagnostic(path); err == nil {
+			entry.Text = entry.Text + "\n" + stats.Summary()
+			entry.Time, entry.HasTimeZone = stats.Time, stats.HasTimeZone
+		}
+		l = append(l, entry)
 	}
 	return l
 }
 
 func isIgnortedIndexingFile(path string) bool {
-	if isIndexingFolder(path) && (filepath.Ext(path) == ".json" ||
-		strings.Contains(filepath.Base(path), "changed-files-pushing-events.json") ||
-		strings.Contains(filepath.Base(path), "report.html") ||
-		strings.Contains(filepath.Base(path), "shared-index-events.json")) {
+	if isIndexingFolder(path) && !analyzer.IsIndexingEventsFile(path) && (filepath.Ext(path) == ".json" ||
+		strings.Contains(filepath.Base(path), "report.html")) {
 		return true
 	}
 	return false
 }
 func isIndexingFile(path string) bool {
+	if isIndexingFolder(path) && analyzer.IsIndexingEventsFile(path) {
+		return true
+	}
 	timeStamp := analyzer.GetRegexNamedCapturedGroups(`diagnostic-(?P<Year>\d{4})-(?P<Month>\d{2})-(?P<Day>\d{2})-(?P<Hours>\d{2})-(?P<Minutes>\d{2})-(?P<Seconds>\d{2}).*.html`, path)
 	if len(timeStamp) > 0 {
 		return true
@@ -54,17 +66,19 @@ func isIndexingFile(path string) bool {
 	return false
 }
 
-func getTimeStampFromIndexingFile(path string) time.Time {
-	timeStamp := analyzer.GetRegexNamedCapturedGroups(`diagnostic-(?P<Year>\d{4})-(?P<Month>\d{2})-(?P<Day>\d{2})-(?P<Hours>\d{2})-(?P<Minutes>\d{2})-(?P<Seconds>\d{2}).*.html`, path)
-	if len(timeStamp) == 0 {
-		return time.Time{}
+//getIndexingHistoryOfFolder parses indexing reports of the project, so events could be linked to them
+func getIndexingHistoryOfFolder(dir string) (history analyzer.IndexingHistory) {
+	reports, _ := filepath.Glob(filepath.Join(dir, "diagnostic-*.html"))
+	for _, report := range reports {
+		if stats, err := analyzer.ParseIndexingDiagnostic(report); err == nil {
+			history = append(history, stats)
+		}
 	}
-	s := fmt.Sprintf("%s-%s-%sT%s:%s:%sZ", timeStamp["Year"], timeStamp["Month"], timeStamp["Day"], timeStamp["Hours"], timeStamp["Minutes"], timeStamp["Seconds"])
-	t, err := time.Parse(time.RFC3339, s)
-	if err != nil {
-		log.Printf("getTimeStampFromIndexingFile failed. path: %s, error: %s", path, err)
-	}
-	return t
+	return history
+}
+
+func getTimeStampFromIndexingFile(path string) time.Time {
+	return analyzer.IndexingReportTime(path)
 }
 
 func getIndexingProjectName(path string) string {
@@ -0,0 +1,20 @@
+package entities
+
+import (
+	"log_analyzer/backend/analyzer"
+)
+
+func init() {
+	CurrentAnalyzer.AddDynamicEntity(analyzer.DynamicEntity{
+		Name:                  "JVM Crash",
+		ConvertPathToLogs:     parseJVMCrashLog,
+		CheckPath:             analyzer.IsJVMCrashLog,
+		GetDisplayName:        getDisplayName,
+		LineHighlightingColor: "#e55757",
+	})
+}
+
+//parseJVMCrashLog represents fatal error log (hs_err_pid*.log) as a single CRASH entry
+func parseJVMCrashLog(path string) analyzer.Logs {
+	return analyzer.ParseCrashLog(path).ConvertToLogs()
+}
@@ -0,0 +1,42 @@
+package entities
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"log_analyzer/backend/analyzer"
+	"log_analyzer/backend/analyzer/installedIDEs"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	CurrentAnalyzer.AddStaticEntity(analyzer.StaticEntity{
+		Name:                "product-info.json",
+		ConvertToStaticInfo: parseProductInfo,
+		CheckPath:           isProductInfo,
+	})
+}
+
+func isProductInfo(path string) bool {
+	return filepath.Base(path) == "product-info.json"
+}
+
+//parseProductInfo takes the product name, version and build from product-info.json of IDE installation
+func parseProductInfo(path string) (a analyzer.StaticInfo) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Printf("parseProductInfo failed. path: %s, error: %s", path, err)
+		return a
+	}
+	var info installedIDEs.IdeInfo
+	if err = json.Unmarshal(content, &info); err != nil {
+		log.Printf("parseProductInfo failed. path: %s, error: %s", path, err)
+		return a
+	}
+	a.IDE = strings.TrimSpace(info.Name + " " + info.Version)
+	if len(info.BuildNumber) > 0 {
+		a.Build = "#" + info.ProductCode + "-" + info.BuildNumber
+	}
+	return a
+}
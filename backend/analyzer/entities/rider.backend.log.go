@@ -68,13 +68,14 @@ func init() {
 	})
 }
 
-// ignore files bigger than 49MB
+// hide files bigger than MaxVisibleRiderLogSizeMB setting
 func isBackendLogVisible(path string) bool {
+	limit := analyzer.GetParsingSettings().MaxVisibleRiderLogSizeMB
 	f, err := os.Stat(path)
-	if err != nil {
+	if err != nil || limit <= 0 {
 		return true
 	}
-	if f.Size() >= 47*1024*1024 {
+	if f.Size() >= int64(limit)*1024*1024 {
 		log.Printf("File %s is too big (%d MB) to be displayed", path, f.Size()/(1024*1024))
 		return false
 	}
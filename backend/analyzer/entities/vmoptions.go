@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"log_analyzer/backend/analyzer"
+	"log_analyzer/backend/analyzer/installedIDEs"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	CurrentAnalyzer.AddStaticEntity(analyzer.StaticEntity{
+		Name:                "vmoptions",
+		ConvertToStaticInfo: parseJVMSettings,
+		CheckPath:           isJVMSettingsFile,
+	})
+}
+
+//isJVMSettingsFile matches idea64.vmoptions, idea64.exe.vmoptions, idea.properties, etc
+func isJVMSettingsFile(path string) bool {
+	return strings.HasSuffix(path, ".vmoptions") || filepath.Base(path) == "idea.properties"
+}
+
+func parseJVMSettings(path string) (a analyzer.StaticInfo) {
+	if strings.HasSuffix(path, ".vmoptions") {
+		a.JVMSettings = analyzer.ParseVMOptions(analyzer.ReadOptionsFile(path))
+		a.JVMSettings.VMOptionsFile = filepath.Base(path)
+		a.JVMSettings.VMOptionsPath = path
+	} else {
+		a.JVMSettings = analyzer.ParseIdeaProperties(installedIDEs.ReadIdeProperties(path))
+		a.JVMSettings.PropertiesFile = filepath.Base(path)
+		a.JVMSettings.PropertiesPath = path
+	}
+	return a
+}
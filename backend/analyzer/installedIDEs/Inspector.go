@@ -0,0 +1,375 @@
+package installedIDEs
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"log_analyzer/backend/analyzer"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+var (
+	//defaultPluginsDirLocation is used if neither idea.plugins.path nor idea.config.path is set
+	defaultPluginsDirLocation = map[string]string{
+		"darwin":  UserHomeDir() + "/Library/Application Support/JetBrains/{dataDirectoryName}/plugins/",
+		"linux":   UserHomeDir() + "/.local/share/JetBrains/{dataDirectoryName}/",
+		"windows": os.Getenv("APPDATA") + "/JetBrains/{dataDirectoryName}/plugins/",
+	}
+	//ideaPropertyReferenceRegex matches references like ${user.home} or ${idea.config.path} in idea.properties values
+	ideaPropertyReferenceRegex = regexp.MustCompile(`\$\{([^}]+)}`)
+)
+
+//IdePath is the effective location of the IDE directory and the place it is defined in
+type IdePath struct {
+	Property string
+	Path     string
+	Source   string // Source is the file the property is set in, or "default"
+	Exists   bool
+}
+
+//InstalledPlugin is the plugin found in the plugins directory of the IDE
+type InstalledPlugin struct {
+	ID         string
+	Name       string
+	Version    string
+	Vendor     string
+	SinceBuild string
+	UntilBuild string
+	Path       string
+}
+
+//DirectorySize is the size of the folder inside the system directory of the IDE, for example caches or index
+type DirectorySize struct {
+	Name string
+	Path string
+	Size int64
+}
+
+//VmOption is the JVM option in effect and the file it comes from
+type VmOption struct {
+	Option string
+	File   string
+}
+
+//Inspection is the read-only summary of the local IDE setup: its directories, plugins, caches and JVM options
+type Inspection struct {
+	IDE            IDE
+	Paths          []IdePath
+	Plugins        []InstalledPlugin
+	Directories    []DirectorySize
+	VmOptionsFiles []string
+	VmOptions      []VmOption
+	JVMSettings    analyzer.JVMSettings
+}
+
+type pluginDescriptor struct {
+	ID          string `xml:"id"`
+	Name        string `xml:"name"`
+	Version     string `xml:"version"`
+	Vendor      string `xml:"vendor"`
+	IdeaVersion struct {
+		SinceBuild string `xml:"since-build,attr"`
+		UntilBuild string `xml:"until-build,attr"`
+	} `xml:"idea-version"`
+}
+
+//Inspect collects paths, plugins, sizes of system directory folders and vmoptions of the installation
+func Inspect(ide IDE) (i Inspection) {
+	i.IDE = ide
+	i.VmOptionsFiles = ide.vmOptionsFiles()
+	i.VmOptions = effectiveVmOptions(i.VmOptionsFiles)
+	var options []string
+	for _, option := range i.VmOptions {
+		options = append(options, option.Option)
+	}
+	i.JVMSettings = analyzer.ParseVMOptions(options)
+	i.Paths = ide.effectivePaths(i.VmOptions)
+	for _, path := range i.Paths {
+		switch path.Property {
+		case "idea.plugins.path":
+			i.Plugins = findInstalledPlugins(path.Path)
+		case "idea.system.path":
+			i.Directories = getDirectorySizes(path.Path)
+		}
+	}
+	return i
+}
+
+//vmOptionsFiles returns vmoptions files in the order they are read by the launcher: the file of the installation, the file created by Toolbox
+//and the file of the user (or the file from <IDE>_VM_OPTIONS environment variable instead of it). Options of the later files win.
+func (ide IDE) vmOptionsFiles() (files []string) {
+	productInfoDir := filepath.Dir(ide.Package + IdeProductInfoRelatedToInstallationPath[runtime.GOOS])
+	for _, launch := range ide.Info.Launch {
+		if len(launch.VmOptionsFilePath) > 0 && strings.EqualFold(launch.Os, productInfoOsNames[runtime.GOOS]) {
+			files = append(files, filepath.Join(productInfoDir, filepath.FromSlash(launch.VmOptionsFilePath)))
+		}
+	}
+	files = append(files, ide.Package+".vmoptions")
+	if fromEnv := os.Getenv(strings.ToUpper(GetIdeBasefileName(ide.Binary)) + "_VM_OPTIONS"); len(fromEnv) > 0 && FileExists(fromEnv) {
+		files = append(files, fromEnv)
+	} else {
+		userOptions, _ := filepath.Glob(filepath.Join(ide.defaultConfigDir(), "*.vmoptions"))
+		files = append(files, userOptions...)
+	}
+	return existingFiles(files)
+}
+
+//ideaPropertiesFiles returns idea.properties files of the installation in the order of their priority
+func (ide IDE) ideaPropertiesFiles() (files []string) {
+	for _, location := range getOsDependentDir(possibleIdeaPropertiesFileLocations) {
+		location = strings.Replace(location, "{IDE_BasefileName}", strings.ToUpper(GetIdeBasefileName(ide.Binary)), -1)
+		location = strings.Replace(location, "{dataDirectoryName}", ide.Info.DataDirectoryName, -1)
+		location = strings.Replace(location, "{ideaPackage}", ide.Package, -1)
+		files = append(files, sandboxedLocation(os.ExpandEnv(location), ide.Binary))
+	}
+	return existingFiles(files)
+}
+
+func (ide IDE) defaultConfigDir() string {
+	return sandboxedLocation(os.ExpandEnv(strings.Replace(defaultConfigDirLocation[runtime.GOOS], "{dataDirectoryName}", ide.Info.DataDirectoryName, -1)), ide.Binary)
+}
+
+//existingFiles removes duplicates and files that do not exist
+func existingFiles(files []string) (existing []string) {
+	seen := make(map[string]bool)
+	for _, f := range files {
+		f = filepath.Clean(f)
+		if info, err := os.Stat(f); err == nil && !info.IsDir() && !seen[f] {
+			seen[f] = true
+			existing = append(existing, f)
+		}
+	}
+	return existing
+}
+
+//effectiveVmOptions combines options of the files, an option redefined by a later file replaces the earlier one
+func effectiveVmOptions(files []string) (options []VmOption) {
+	index := make(map[string]int)
+	for _, file := range files {
+		for _, option := range analyzer.ReadOptionsFile(file) {
+			key := analyzer.VMOptionKey(option)
+			if i, found := index[key]; found {
+				options[i] = VmOption{Option: option, File: file}
+				continue
+			}
+			index[key] = len(options)
+			options = append(options, VmOption{Option: option, File: file})
+		}
+	}
+	return options
+}
+
+//effectivePaths resolves config, system, plugins and log directories. -D options of vmoptions win over idea.properties, then defaults are used.
+func (ide IDE) effectivePaths(vmOptions []VmOption) (paths []IdePath) {
+	type definition struct{ value, source string }
+	defined := make(map[string]definition)
+	for _, file := range ide.ideaPropertiesFiles() {
+		for name, value := range ReadIdeProperties(file) {
+			if _, found := defined[name]; !found {
+				defined[name] = definition{value, file}
+			}
+		}
+	}
+	for _, option := range vmOptions {
+		if idx := strings.IndexByte(option.Option, '='); strings.HasPrefix(option.Option, "-D") && idx > 0 {
+			defined[option.Option[2:idx]] = definition{option.Option[idx+1:], option.File}
+		}
+	}
+	resolved := make(map[string]string)
+	resolve := func(property string, defaultPath string) {
+		path, source := defaultPath, "default"
+		if d, found := defined[property]; found && len(d.value) > 0 {
+			path, source = expandIdeaProperty(d.value, resolved), d.source
+		}
+		if len(path) > 0 {
+			path = filepath.Clean(path)
+		}
+		resolved[property] = path
+		paths = append(paths, IdePath{Property: property, Path: path, Source: source, Exists: len(path) > 0 && FileExists(path)})
+	}
+	resolve("idea.config.path", ide.defaultConfigDir())
+	resolve("idea.system.path", sandboxedLocation(os.ExpandEnv(strings.Replace(defaultSystemDirLocation[runtime.GOOS], "{dataDirectoryName}", ide.Info.DataDirectoryName, -1)), ide.Binary))
+	defaultPluginsDir := sandboxedLocation(os.ExpandEnv(strings.Replace(defaultPluginsDirLocation[runtime.GOOS], "{dataDirectoryName}", ide.Info.DataDirectoryName, -1)), ide.Binary)
+	if _, found := defined["idea.config.path"]; found {
+		defaultPluginsDir = filepath.Join(resolved["idea.config.path"], "plugins")
+	}
+	resolve("idea.plugins.path", defaultPluginsDir)
+	resolve("idea.log.path", ide.Info.LogsDirectory)
+	return paths
+}
+
+//expandIdeaProperty replaces ${user.home}, references to already resolved properties and environment variables in the value
+func expandIdeaProperty(value string, resolved map[string]string) string {
+	value = ideaPropertyReferenceRegex.ReplaceAllStringFunc(value, func(reference string) string {
+		name := reference[2 : len(reference)-1]
+		if name == "user.home" {
+			return UserHomeDir()
+		}
+		if path, found := resolved[name]; found {
+			return path
+		}
+		return os.Getenv(name)
+	})
+	if strings.HasPrefix(value, "~/") {
+		value = UserHomeDir() + value[1:]
+	}
+	return value
+}
+
+//findInstalledPlugins reads plugin.xml of every plugin folder or jar in the plugins directory
+func findInstalledPlugins(pluginsDir string) (plugins []InstalledPlugin) {
+	entries, err := os.ReadDir(pluginsDir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		path := filepath.Join(pluginsDir, entry.Name())
+		var descriptor *pluginDescriptor
+		switch {
+		case entry.IsDir():
+			descriptor = readPluginFolderDescriptor(path)
+		case strings.EqualFold(filepath.Ext(path), ".jar"):
+			descriptor = readJarPluginDescriptor(path)
+		default:
+			continue
+		}
+		plugin := InstalledPlugin{Name: entry.Name(), Path: path}
+		if descriptor != nil {
+			plugin = InstalledPlugin{
+				ID:         descriptor.ID,
+				Name:       strings.TrimSpace(descriptor.Name),
+				Version:    strings.TrimSpace(descriptor.Version),
+				Vendor:     strings.TrimSpace(descriptor.Vendor),
+				SinceBuild: descriptor.IdeaVersion.SinceBuild,
+				UntilBuild: descriptor.IdeaVersion.UntilBuild,
+				Path:       path,
+			}
+			if len(plugin.ID) == 0 {
+				plugin.ID = plugin.Name
+			}
+		}
+		plugins = append(plugins, plugin)
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return strings.ToLower(plugins[i].Name) < strings.ToLower(plugins[j].Name)
+	})
+	return plugins
+}
+
+//readPluginFolderDescriptor looks for META-INF/plugin.xml in the folder itself and in jars of its lib folder
+func readPluginFolderDescriptor(folder string) *pluginDescriptor {
+	if content, err := os.ReadFile(filepath.Join(folder, "META-INF", "plugin.xml")); err == nil {
+		return parsePluginDescriptor(bytes.NewReader(content))
+	}
+	jars, _ := filepath.Glob(filepath.Join(folder, "lib", "*.jar"))
+	for _, jar := range jars {
+		if descriptor := readJarPluginDescriptor(jar); descriptor != nil {
+			return descriptor
+		}
+	}
+	return nil
+}
+
+func readJarPluginDescriptor(jar string) *pluginDescriptor {
+	r, err := zip.OpenReader(jar)
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if f.Name != "META-INF/plugin.xml" {
+			continue
+		}
+		content, err := f.Open()
+		if err != nil {
+			log.Printf("Could not read plugin.xml of %s: %s", jar, err)
+			return nil
+		}
+		defer content.Close()
+		return parsePluginDescriptor(content)
+	}
+	return nil
+}
+
+func parsePluginDescriptor(content io.Reader) *pluginDescriptor {
+	var descriptor pluginDescriptor
+	decoder := xml.NewDecoder(content)
+	decoder.Strict = false
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	if err := decoder.Decode(&descriptor); err != nil {
+		log.Printf("Could not parse plugin.xml: %s", err)
+		return nil
+	}
+	if len(descriptor.ID) == 0 && len(descriptor.Name) == 0 {
+		return nil
+	}
+	return &descriptor
+}
+
+//getDirectorySizes returns sizes of the folders inside the system directory, the biggest goes first
+func getDirectorySizes(systemDir string) (sizes []DirectorySize) {
+	entries, err := os.ReadDir(systemDir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(systemDir, entry.Name())
+		sizes = append(sizes, DirectorySize{Name: entry.Name(), Path: path, Size: getDirectorySize(path)})
+	}
+	sort.Slice(sizes, func(i, j int) bool {
+		return sizes[i].Size > sizes[j].Size
+	})
+	return sizes
+}
+
+func getDirectorySize(folder string) (size int64) {
+	_ = filepath.WalkDir(folder, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+//DisplaySize returns the size in human-readable units, for example 1.5 GB
+func (d DirectorySize) DisplaySize() string {
+	size := float64(d.Size)
+	for _, unit := range []string{"B", "KB", "MB", "GB"} {
+		if size < 1024 {
+			return fmt.Sprintf("%.1f %s", size, unit)
+		}
+		size = size / 1024
+	}
+	return fmt.Sprintf("%.1f TB", size)
+}
+
+//ConvertToHTML renders the inspection based on IdeInspector.gohtml template
+func (i Inspection) ConvertToHTML() string {
+	var tpl bytes.Buffer
+	t := template.Must(template.New("IdeInspector.gohtml").
+		ParseFS(tmplFS, "IdeInspector.gohtml"))
+	err := t.Execute(&tpl, i)
+	if err != nil {
+		log.Printf("Template IdeInspector.gohtml execution failed. Error: %s", err.Error())
+	}
+	return tpl.String()
+}
@@ -4,25 +4,17 @@ import (
 	"bufio"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io/ioutil"
 	"log"
-	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"sort"
 	"strings"
 	"sync"
-	"time"
 )
 
-type ideInfoFromDebugger struct {
-	Name        string `json:"name"`
-	ProductName string `json:"productName"`
-	BuildNumber string `json:"buildNumber"`
-}
-
 type IdeInfo struct {
 	Name              string
 	Version           string
@@ -39,19 +31,25 @@ type IdeInfo struct {
 	} `json:"launch"`
 }
 type IDE struct {
-	Binary  string
-	Package string
-	Running bool
-	Info    IdeInfo
+	Binary          string
+	Package         string
+	Running         bool
+	Info            IdeInfo
+	SystemDirectory string // SystemDirectory is the folder with caches and indexes of the IDE. Empty if it does not exist
 }
 
 var (
-	IdePropertiesMap                        = map[string]string{}
 	IdeProductInfoRelatedToInstallationPath = map[string]string{
 		"darwin":  "/Contents/Resources/product-info.json",
 		"linux":   "/product-info.json",
 		"windows": "/product-info.json",
 	}
+	// productInfoOsNames are names of OS used in "launch" section of product-info.json
+	productInfoOsNames = map[string]string{
+		"darwin":  "macOS",
+		"linux":   "Linux",
+		"windows": "Windows",
+	}
 	possibleBaseFileNames              = []string{"appcode", "clion", "datagrip", "dataspell", "goland", "idea", "phpstorm", "pycharm", "rubymine", "webstorm", "rider", "Draft"}
 	IdeBinaryRelatedToInstallationPath = map[string]string{
 		"darwin":  "/Contents/MacOS/{possibleBaseFileName}",
@@ -59,8 +57,23 @@ var (
 		"windows": "/bin/{possibleBaseFileName}64.exe",
 	}
 	possibleBinariesPaths = map[string][]string{
-		"darwin":  {"/Applications/*.app/Contents/MacOS/{possibleBaseFileName}", "$HOME/Library/Application Support/JetBrains/Toolbox/apps/*/ch-*/*/*.app/Contents/MacOS/{possibleBaseFileName}"},
-		"linux":   {"$HOME/.local/share/JetBrains/Toolbox/apps/*/ch-*/*/bin/{possibleBaseFileName}.sh"},
+		"darwin": {"/Applications/*.app/Contents/MacOS/{possibleBaseFileName}", "$HOME/Library/Application Support/JetBrains/Toolbox/apps/*/ch-*/*/*.app/Contents/MacOS/{possibleBaseFileName}"},
+		"linux": {
+			"$HOME/.local/share/JetBrains/Toolbox/apps/*/ch-*/*/bin/{possibleBaseFileName}.sh",
+			"$HOME/.local/share/JetBrains/Toolbox/apps/*/bin/{possibleBaseFileName}.sh",
+			"/opt/*/bin/{possibleBaseFileName}.sh",
+			"/opt/JetBrains/*/bin/{possibleBaseFileName}.sh",
+			"/usr/local/*/bin/{possibleBaseFileName}.sh",
+			"/usr/share/*/bin/{possibleBaseFileName}.sh",
+			"$HOME/apps/*/bin/{possibleBaseFileName}.sh",
+			"$HOME/Applications/*/bin/{possibleBaseFileName}.sh",
+			"$HOME/.local/share/*/bin/{possibleBaseFileName}.sh",
+			"/snap/*/current/bin/{possibleBaseFileName}.sh",
+			"/var/lib/flatpak/app/com.jetbrains.*/current/active/files/*/bin/{possibleBaseFileName}.sh",
+			"/var/lib/flatpak/app/com.jetbrains.*/current/active/files/extra/*/bin/{possibleBaseFileName}.sh",
+			"$HOME/.local/share/flatpak/app/com.jetbrains.*/current/active/files/*/bin/{possibleBaseFileName}.sh",
+			"$HOME/.local/share/flatpak/app/com.jetbrains.*/current/active/files/extra/*/bin/{possibleBaseFileName}.sh",
+		},
 		"windows": {os.Getenv("HOMEDRIVE") + "/Program Files/JetBrains/*" + IdeBinaryRelatedToInstallationPath["windows"], os.Getenv("LOCALAPPDATA") + "/JetBrains/Toolbox/apps/*/ch-*/*" + IdeBinaryRelatedToInstallationPath["windows"]},
 	}
 	defaultLogsDirLocation = map[string]string{
@@ -78,11 +91,25 @@ var (
 		"linux":   "${HOME}/.cache/JetBrains/{dataDirectoryName}/",
 		"windows": os.Getenv("LOCALAPPDATA") + "/JetBrains/{dataDirectoryName}/",
 	}
+	//systemDiagnosticFolders are the masks of diagnostic folders inside the system directory
+	systemDiagnosticFolders = []string{"indexing-diagnostic", "threadDumps-*", "log/indexing-diagnostic", "log/threadDumps-*"}
+	lastInstallations       []IDE // lastInstallations are found by the latest GetIdeInstallations call
+	lastInstallationsMutex  sync.Mutex
+	idePropertiesCache      = map[string]map[string]string{} // idePropertiesCache keeps properties of idea.properties files per IDE binary
+	idePropertiesCacheMutex sync.Mutex
+	customSearchRoots       []string // customSearchRoots are folders configured by user that contain IDE installations or are installations themselves
+	customSearchRootsMutex  sync.Mutex
+	//flatpakAppRegex extracts the application id of IDE installed with flatpak, for example com.jetbrains.IntelliJ-IDEA-Community
+	flatpakAppRegex = regexp.MustCompile(`/flatpak/app/([^/]+)/`)
 )
 
 func GetIdeInstallations() (ides []IDE) {
-	runningIDEs := getRunningIdes()
+	runningIDEs := NewRunningIDEsDetector().Detect()
 	log.Printf("Scanning system for IDE installations")
+	// idea.properties could be changed since the previous scan
+	idePropertiesCacheMutex.Lock()
+	idePropertiesCache = map[string]map[string]string{}
+	idePropertiesCacheMutex.Unlock()
 	var installedIdes []string
 	installedIdes, _ = findInstalledIdePackages()
 
@@ -90,84 +117,110 @@ func GetIdeInstallations() (ides []IDE) {
 		info, _ := getIdeInfoByPackage(idePackage)
 		binary, _ := getIdeBinaryByPackage(idePackage)
 		info.LogsDirectory = getIdeLogsDir(binary)
-		isRunning := checkIfInstallationRunning(runningIDEs, info)
 		if info.LogsDirectory != "" {
-			ides = append(ides, IDE{
-				Binary:  binary,
-				Package: idePackage,
-				Running: isRunning,
-				Info:    info,
-			})
+			ide := IDE{
+				Binary:          binary,
+				Package:         idePackage,
+				Info:            info,
+				SystemDirectory: getIdeSystemDir(binary, info),
+			}
+			ide.Running = isInstallationRunning(runningIDEs, ide)
+			ides = append(ides, ide)
 			//log.Printf("[runnning: %v] [%v] %v %v (%v-%v) - %v \n", isRunning, i, info.Name, info.Version, info.ProductCode, info.BuildNumber, beautifyPackageName(idePackage))
 		}
 	}
 	//sort them by running state. Running ones first
-	sort.Slice(ides, func(i int, j int) bool {
-		return ides[i].Running
+	sort.SliceStable(ides, func(i int, j int) bool {
+		return ides[i].Running && !ides[j].Running
 	})
+	lastInstallationsMutex.Lock()
+	lastInstallations = ides
+	lastInstallationsMutex.Unlock()
 	return ides
 }
 
-func checkIfInstallationRunning(runningIDEs []ideInfoFromDebugger, info IdeInfo) bool {
-	for _, e := range runningIDEs {
-		if e.BuildNumber == info.BuildNumber && strings.Contains(info.Name, e.ProductName) {
-			return true
+//FindInstallation returns the IDE with the given logs directory among installations found by the latest scan
+func FindInstallation(logsDirectory string) (IDE, bool) {
+	lastInstallationsMutex.Lock()
+	defer lastInstallationsMutex.Unlock()
+	for _, ide := range lastInstallations {
+		if filepath.Clean(ide.Info.LogsDirectory) == filepath.Clean(logsDirectory) {
+			return ide, true
 		}
 	}
-	return false
+	return IDE{}, false
 }
 
-func getRunningIdes() (ides []ideInfoFromDebugger) {
-	var wg sync.WaitGroup
-	for i := 63342; i < 63392; i++ {
-		wg.Add(1)
-		go func(i int) {
-			url := fmt.Sprintf("http://localhost:%d/api/about", i)
-			if ideInfo, err := getIdeInfoFromPort(url); err == nil {
-				ides = append(ides, ideInfo)
+//SystemDiagnosticFolders returns indexing diagnostic and thread dumps folders of the system directory that are not inside the logs directory
+func (ide IDE) SystemDiagnosticFolders() (folders []string) {
+	if len(ide.SystemDirectory) == 0 {
+		return nil
+	}
+	for _, mask := range systemDiagnosticFolders {
+		matches, _ := filepath.Glob(filepath.Join(ide.SystemDirectory, filepath.FromSlash(mask)))
+		for _, match := range matches {
+			if rel, err := filepath.Rel(ide.Info.LogsDirectory, match); err == nil && !strings.HasPrefix(rel, "..") {
+				continue
 			}
-			defer wg.Done()
-		}(i)
+			if info, err := os.Stat(match); err == nil && info.IsDir() {
+				folders = append(folders, match)
+			}
+		}
 	}
-	wg.Wait()
-	return ides
+	return folders
 }
 
-func getIdeInfoFromPort(url string) (ideInfoFromDebugger, error) {
-	client := http.Client{
-		Timeout: time.Second,
-	}
-	res, err := client.Get(url)
-	if err != nil {
-		//log.Printf("Error getting HTTP response. err: %s", err.Error())
-		return ideInfoFromDebugger{}, err
-	}
-	var ideInstance ideInfoFromDebugger
-	content, _ := ioutil.ReadAll(res.Body)
-	_ = res.Body.Close()
-	ideInstance = parseRunningIdeInfo(content)
-	log.Printf("Found running IDE %v at url %v", ideInstance, url)
-	return ideInstance, err
+//SetCustomSearchRoots sets folders that are searched for IDE installations in addition to the standard locations
+func SetCustomSearchRoots(roots []string) {
+	customSearchRootsMutex.Lock()
+	defer customSearchRootsMutex.Unlock()
+	customSearchRoots = roots
 }
 
-func parseRunningIdeInfo(body []byte) ideInfoFromDebugger {
-	ideInfo := ideInfoFromDebugger{}
-	jsonErr := json.Unmarshal(body, &ideInfo)
-	if jsonErr != nil {
-		log.Printf("Could not unmarshall JSON, %s", jsonErr.Error())
-	}
-	return ideInfo
+//getCustomSearchMasks returns masks of IDE binaries for the configured roots: the root could be an installation itself, contain installations or vendor folders with them
+func getCustomSearchMasks() (masks []string) {
+	customSearchRootsMutex.Lock()
+	defer customSearchRootsMutex.Unlock()
+	binary := IdeBinaryRelatedToInstallationPath[runtime.GOOS]
+	for _, root := range customSearchRoots {
+		root = filepath.ToSlash(filepath.Clean(root))
+		masks = append(masks, root+binary, root+"/*"+binary, root+"/*/*"+binary)
+	}
+	return masks
 }
 
 func findInstalledIdePackages() (installedIdes []string, err error) {
-	for _, path := range getOsDependentDir(possibleBinariesPaths) {
+	found := make(map[string]bool)
+	for _, path := range append(getOsDependentDir(possibleBinariesPaths), getCustomSearchMasks()...) {
 		var foundInstallations []string
 		foundInstallations, err = findIdeInstallationsByMask(path)
-		installedIdes = append(installedIdes, foundInstallations...)
+		for _, installation := range foundInstallations {
+			if len(installation) == 0 || found[filepath.Clean(installation)] {
+				continue
+			}
+			found[filepath.Clean(installation)] = true
+			installedIdes = append(installedIdes, installation)
+		}
 	}
 	return installedIdes, err
 }
 
+//sandboxedLocation moves ~/.cache, ~/.config and ~/.local/share locations of flatpak IDE into the folder of the application, as its XDG directories are redirected there
+func sandboxedLocation(location string, ideaBinary string) string {
+	match := flatpakAppRegex.FindStringSubmatch(filepath.ToSlash(ideaBinary))
+	if match == nil {
+		return location
+	}
+	appDir := UserHomeDir() + "/.var/app/" + match[1]
+	for xdgDir, appSubDir := range map[string]string{".cache": "cache", ".config": "config", ".local/share": "data"} {
+		prefix := UserHomeDir() + "/" + xdgDir + "/"
+		if strings.HasPrefix(location, prefix) {
+			return appDir + "/" + appSubDir + "/" + strings.TrimPrefix(location, prefix)
+		}
+	}
+	return location
+}
+
 func getOsDependentDir(fromVariable map[string][]string) []string {
 	if len(fromVariable[runtime.GOOS]) > 0 {
 		return fromVariable[runtime.GOOS]
@@ -263,7 +316,7 @@ func getIdeLogsDir(ideaBinary string) (logsDir string) {
 		log.Printf("getIdeInfoByBinary failed. ideaBinary: %s, Error: %s", ideaBinary, err)
 	}
 	logsDir = strings.Replace(defaultLogsDirLocation[runtime.GOOS], "{dataDirectoryName}", installationInfo.DataDirectoryName, -1)
-	logsDir = os.ExpandEnv(logsDir)
+	logsDir = sandboxedLocation(os.ExpandEnv(logsDir), ideaBinary)
 	if FileExists(logsDir) {
 		return logsDir
 	} else {
@@ -271,15 +324,30 @@ func getIdeLogsDir(ideaBinary string) (logsDir string) {
 		return ""
 	}
 }
-func GetIdePropertyByName(name string, ideaBinary string) (value string) {
-	if len(IdePropertiesMap) == 0 {
-		IdePropertiesMap = GetIdeProperties(ideaBinary)
+
+// getIdeSystemDir returns the value of idea.system.path property or the default system directory of the IDE if it exists
+func getIdeSystemDir(ideaBinary string, info IdeInfo) string {
+	if value := GetIdePropertyByName("idea.system.path", ideaBinary); len(value) != 0 && FileExists(value) {
+		return value
 	}
-	if _, ok := IdePropertiesMap[name]; ok {
-		return IdePropertiesMap[name]
+	systemDir := sandboxedLocation(os.ExpandEnv(strings.Replace(defaultSystemDirLocation[runtime.GOOS], "{dataDirectoryName}", info.DataDirectoryName, -1)), ideaBinary)
+	if FileExists(systemDir) {
+		return systemDir
 	}
 	return ""
 }
+
+// GetIdePropertyByName returns the property of the IDE. Properties are read once per binary and cached until the next installations scan
+func GetIdePropertyByName(name string, ideaBinary string) (value string) {
+	idePropertiesCacheMutex.Lock()
+	defer idePropertiesCacheMutex.Unlock()
+	properties, found := idePropertiesCache[ideaBinary]
+	if !found {
+		properties = GetIdeProperties(ideaBinary)
+		idePropertiesCache[ideaBinary] = properties
+	}
+	return properties[name]
+}
 func getIdeInfoByBinary(ideaBinary string) (parameterValue IdeInfo, err error) {
 	return getIdeInfoByPackage(getIdeIdePackageByBinary(ideaBinary))
 }
@@ -293,7 +361,7 @@ func GetIdeProperties(ideaBinary string) (collectedOptions map[string]string) {
 		possibleIdeaOptionsFile := strings.Replace(possibleIdeaPropertiesFileLocation, "{IDE_BasefileName}", strings.ToUpper(GetIdeBasefileName(ideaBinary)), -1)
 		possibleIdeaOptionsFile = strings.Replace(possibleIdeaOptionsFile, "{dataDirectoryName}", InstallationInfo.DataDirectoryName, -1)
 		possibleIdeaOptionsFile = strings.Replace(possibleIdeaOptionsFile, "{ideaPackage}", ideaPackage, -1)
-		possibleIdeaOptionsFile = os.ExpandEnv(possibleIdeaOptionsFile)
+		possibleIdeaOptionsFile = sandboxedLocation(os.ExpandEnv(possibleIdeaOptionsFile), ideaBinary)
 		if FileExists(possibleIdeaOptionsFile) {
 			//log.Println("found idea.properties file at: \"" + possibleIdeaOptionsFile + "\"")
 			fillIdePropertiesMap(possibleIdeaOptionsFile, collectedOptions)
@@ -326,6 +394,13 @@ func DetectInstallationByInnerPath(providedPath string, returnBinary bool) (idea
 	}
 	return "", errors.New("Could not detect IDE by \"" + providedPath + "\" path")
 }
+//ReadIdeProperties returns properties defined in idea.properties file. The first definition of the property wins.
+func ReadIdeProperties(ideaPropertiesFile string) map[string]string {
+	properties := make(map[string]string)
+	fillIdePropertiesMap(ideaPropertiesFile, properties)
+	return properties
+}
+
 func fillIdePropertiesMap(ideaOptionsFile string, optionsMap map[string]string) {
 	optionsSlice, err := ideaPropertiesFileToSliceOfStrings(ideaOptionsFile)
 	if err != nil {
@@ -370,7 +445,9 @@ func ideaPropertiesFileToSliceOfStrings(ideaPropertiesFile string) (properties [
 	return properties, err
 
 }
+//GetIdeBasefileName returns the base name of the launcher, for example "idea" for idea.sh, idea64.exe or idea
 func GetIdeBasefileName(ideaBinary string) string {
+	ideaBinary = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(ideaBinary, ".sh"), ".exe"), "64")
 	for _, possibleBaseFileName := range possibleBaseFileNames {
 		if strings.HasSuffix(ideaBinary, possibleBaseFileName) {
 			return possibleBaseFileName
@@ -0,0 +1,165 @@
+package installedIDEs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//The built-in server of the IDE listens on the first free port starting from BuiltInServerFirstPort
+const (
+	BuiltInServerFirstPort = 63342
+	BuiltInServerLastPort  = 63391
+)
+
+var (
+	builtInServerPorts      = [2]int{BuiltInServerFirstPort, BuiltInServerLastPort} // builtInServerPorts are the first and the last ports probed by NewRunningIDEsDetector
+	builtInServerPortsMutex sync.Mutex
+)
+
+//buildProductCodeRegex matches product code prefix of the build number, for example "IU-" in IU-222.3345.118
+var buildProductCodeRegex = regexp.MustCompile(`^[A-Z]+-`)
+
+//RunningIDE is the IDE instance that answered on /api/about of its built-in server
+type RunningIDE struct {
+	Port        int
+	Name        string `json:"name"`
+	ProductName string `json:"productName"`
+	ProductCode string `json:"productCode"`
+	BuildNumber string `json:"buildNumber"`
+	HomePath    string `json:"homePath"` // HomePath is the installation path of the instance. Older IDEs do not report it
+}
+
+//RunningIDEsDetector finds running IDEs by probing built-in servers on the ports of Host. It is safe for concurrent use.
+type RunningIDEsDetector struct {
+	Host      string
+	FirstPort int
+	LastPort  int
+	Client    *http.Client
+}
+
+//SetBuiltInServerPorts sets the range of ports probed for running IDEs, for example when built-in server port is changed in IDE settings
+func SetBuiltInServerPorts(first int, last int) {
+	if last < first {
+		log.Printf("Running IDEs port range %d-%d is empty, using %d-%d", first, last, first, first)
+		last = first
+	}
+	builtInServerPortsMutex.Lock()
+	defer builtInServerPortsMutex.Unlock()
+	builtInServerPorts = [2]int{first, last}
+}
+
+func NewRunningIDEsDetector() *RunningIDEsDetector {
+	builtInServerPortsMutex.Lock()
+	defer builtInServerPortsMutex.Unlock()
+	return &RunningIDEsDetector{
+		Host:      "localhost",
+		FirstPort: builtInServerPorts[0],
+		LastPort:  builtInServerPorts[1],
+		Client:    &http.Client{Timeout: time.Second},
+	}
+}
+
+//Detect probes all ports in parallel and returns found instances ordered by port
+func (d *RunningIDEsDetector) Detect() (ides []RunningIDE) {
+	found := make(chan RunningIDE)
+	var wg sync.WaitGroup
+	for port := d.FirstPort; port <= d.LastPort; port++ {
+		wg.Add(1)
+		go func(port int) {
+			defer wg.Done()
+			if ide, err := d.Probe(port); err == nil {
+				found <- ide
+			}
+		}(port)
+	}
+	go func() {
+		wg.Wait()
+		close(found)
+	}()
+	for ide := range found {
+		ides = append(ides, ide)
+	}
+	sort.Slice(ides, func(i, j int) bool {
+		return ides[i].Port < ides[j].Port
+	})
+	return ides
+}
+
+//Probe requests /api/about on the port. Returns error if nothing listens there or the answer does not come from IDE.
+func (d *RunningIDEsDetector) Probe(port int) (RunningIDE, error) {
+	url := fmt.Sprintf("http://%s/api/about?more=true", net.JoinHostPort(d.Host, strconv.Itoa(port)))
+	res, err := d.Client.Get(url)
+	if err != nil {
+		return RunningIDE{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return RunningIDE{}, fmt.Errorf("%s responded with %s", url, res.Status)
+	}
+	ide, err := parseRunningIdeInfo(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return RunningIDE{}, fmt.Errorf("%s: %s", url, err)
+	}
+	ide.Port = port
+	log.Printf("Found running IDE %s (%s) at port %d", ide.Name, ide.BuildNumber, port)
+	return ide, nil
+}
+
+func parseRunningIdeInfo(body io.Reader) (ide RunningIDE, err error) {
+	if err = json.NewDecoder(body).Decode(&ide); err != nil {
+		return ide, err
+	}
+	if len(ide.Name) == 0 || len(ide.BuildNumber) == 0 {
+		return ide, errors.New("response does not describe IDE")
+	}
+	return ide, nil
+}
+
+//Matches checks if the instance is started from the installation. Build numbers should be equal,
+//then the installation path is compared if the instance reports it, otherwise the product name.
+func (r RunningIDE) Matches(ide IDE) bool {
+	if normalizeBuildNumber(r.BuildNumber) != normalizeBuildNumber(ide.Info.BuildNumber) {
+		return false
+	}
+	if len(r.HomePath) > 0 && len(ide.Package) > 0 {
+		return isSameOrInsidePath(ide.Package, r.HomePath)
+	}
+	return len(r.ProductName) > 0 && strings.Contains(ide.Info.Name, r.ProductName)
+}
+
+func normalizeBuildNumber(build string) string {
+	return buildProductCodeRegex.ReplaceAllString(strings.TrimSpace(build), "")
+}
+
+//isSameOrInsidePath checks if path is the folder itself or inside it. On macOS home path of the instance is Contents folder of the .app package.
+func isSameOrInsidePath(folder string, path string) bool {
+	if resolved, err := filepath.EvalSymlinks(folder); err == nil {
+		folder = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	rel, err := filepath.Rel(folder, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+func isInstallationRunning(runningIDEs []RunningIDE, ide IDE) bool {
+	for _, r := range runningIDEs {
+		if r.Matches(ide) {
+			return true
+		}
+	}
+	return false
+}
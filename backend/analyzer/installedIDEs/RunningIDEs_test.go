@@ -0,0 +1,110 @@
+package installedIDEs
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const aboutResponse = `{"name":"IntelliJ IDEA 2022.2","productName":"IntelliJ IDEA","productCode":"IU","buildNumber":"IU-222.3345.118","homePath":"/opt/idea"}`
+
+//listenConsecutivePorts returns listeners on two consecutive ports followed by a closed port
+func listenConsecutivePorts(t *testing.T) (ide net.Listener, other net.Listener, closedPort int) {
+	for attempt := 0; attempt < 20; attempt++ {
+		first, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		port := first.Addr().(*net.TCPAddr).Port
+		second, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port+1))
+		if err != nil {
+			_ = first.Close()
+			continue
+		}
+		closed, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port+2))
+		if err != nil {
+			_ = first.Close()
+			_ = second.Close()
+			continue
+		}
+		_ = closed.Close()
+		return first, second, port + 2
+	}
+	t.Skip("could not find free consecutive ports")
+	return nil, nil, 0
+}
+
+func startServer(t *testing.T, listener net.Listener, body string) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/about" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	_ = server.Listener.Close()
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+}
+
+func TestRunningIDEsDetector(t *testing.T) {
+	ideListener, otherListener, closedPort := listenConsecutivePorts(t)
+	startServer(t, ideListener, aboutResponse)
+	startServer(t, otherListener, `{"status":"ok"}`)
+	idePort := ideListener.Addr().(*net.TCPAddr).Port
+	detector := &RunningIDEsDetector{
+		Host:      "127.0.0.1",
+		FirstPort: idePort,
+		LastPort:  closedPort,
+		Client:    &http.Client{Timeout: time.Second},
+	}
+
+	ide, err := detector.Probe(idePort)
+	if err != nil {
+		t.Fatalf("IDE is not found at port %d: %s", idePort, err)
+	}
+	if ide.Port != idePort || ide.Name != "IntelliJ IDEA 2022.2" || ide.BuildNumber != "IU-222.3345.118" || ide.HomePath != "/opt/idea" {
+		t.Errorf("unexpected IDE info: %+v", ide)
+	}
+	if _, err := detector.Probe(idePort + 1); err == nil {
+		t.Error("server that is not IDE is detected as IDE")
+	}
+	if _, err := detector.Probe(closedPort); err == nil {
+		t.Error("IDE is detected at closed port")
+	}
+
+	ides := detector.Detect()
+	if len(ides) != 1 || ides[0].Port != idePort {
+		t.Errorf("expected the only IDE at port %d, got %+v", idePort, ides)
+	}
+}
+
+func TestRunningIDEMatches(t *testing.T) {
+	app := filepath.FromSlash("/Applications/IntelliJ IDEA.app")
+	installed := IDE{Package: app, Info: IdeInfo{Name: "IntelliJ IDEA 2022.2", BuildNumber: "222.3345.118"}}
+	tests := []struct {
+		name    string
+		running RunningIDE
+		matches bool
+	}{
+		{"home path inside .app package", RunningIDE{BuildNumber: "222.3345.118", HomePath: filepath.Join(app, "Contents")}, true},
+		{"home path is the package", RunningIDE{BuildNumber: "222.3345.118", HomePath: app}, true},
+		{"home path of other installation", RunningIDE{BuildNumber: "222.3345.118", HomePath: filepath.FromSlash("/Applications/IntelliJ IDEA 2.app/Contents")}, false},
+		{"build with product code prefix", RunningIDE{BuildNumber: "IU-222.3345.118", HomePath: filepath.Join(app, "Contents")}, true},
+		{"build with product code prefix without home path", RunningIDE{BuildNumber: "IU-222.3345.118", ProductName: "IntelliJ IDEA"}, true},
+		{"other build", RunningIDE{BuildNumber: "IU-222.4167.29", HomePath: filepath.Join(app, "Contents")}, false},
+		{"other product without home path", RunningIDE{BuildNumber: "222.3345.118", ProductName: "PyCharm"}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if matches := test.running.Matches(installed); matches != test.matches {
+				t.Errorf("expected %v, got %v", test.matches, matches)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package installedIDEs
+
+import (
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+//secretKeyPattern matches names of properties with secret values
+const secretKeyPattern = `[\w.-]*(?:password|passwd|pwd|secret|token|api[_-]?key|credentials?)[\w.-]*`
+
+var (
+	defaultConfigDirLocation = map[string]string{
+		"darwin":  UserHomeDir() + "/Library/Application Support/JetBrains/{dataDirectoryName}/",
+		"linux":   UserHomeDir() + "/.config/JetBrains/{dataDirectoryName}/",
+		"windows": os.Getenv("APPDATA") + "/JetBrains/{dataDirectoryName}/",
+	}
+	//secretRules replace values that should not leave the machine: passwords and tokens of properties, credentials of URLs and authorization headers
+	secretRules = []struct {
+		regex       *regexp.Regexp
+		replacement string
+	}{
+		// quoted values of properties, JSON keys ("password": "x") and XML attributes (password="x")
+		{regexp.MustCompile(`(?i)(` + secretKeyPattern + `"?\s*[=:]\s*)"(?:[^"\\]|\\.)*"`), `${1}"<redacted>"`},
+		{regexp.MustCompile(`(?i)(` + secretKeyPattern + `"?\s*[=:]\s*)[^"\s]\S*`), "${1}<redacted>"},
+		// XML name and value pairs, for example <option name="password" value="x" />
+		{regexp.MustCompile(`(?i)(name="` + secretKeyPattern + `"\s+value=)"[^"]*"`), `${1}"<redacted>"`},
+		{regexp.MustCompile(`(\w+://)[^/\s:@]+:[^/\s@]+@`), "${1}<redacted>@"},
+		{regexp.MustCompile(`(?i)(authorization:\s*\w+\s+)\S+`), "${1}<redacted>"},
+	}
+	textFileRegex = regexp.MustCompile(`(?i)\.(log|txt|properties|vmoptions|json|xml|csv)(\.\d+)?$`)
+)
+
+//CollectSupportBundle writes zip archive similar to "Collect Logs and Diagnostic Data" of the IDE: logs, thread dumps, indexing diagnostics,
+//product-info.json, vmoptions, idea.properties and environment.txt with the summary of the installation. Secrets found in text files are redacted.
+func CollectSupportBundle(ide IDE, zipPath string) (err error) {
+	file, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	w := zip.NewWriter(file)
+	if err = addFolderToBundle(w, ide.Info.LogsDirectory, "", zipPath); err != nil {
+		return err
+	}
+	for _, folder := range ide.SystemDiagnosticFolders() {
+		if err = addFolderToBundle(w, folder, "system/"+filepath.Base(folder), zipPath); err != nil {
+			return err
+		}
+	}
+	productInfo := ide.Package + IdeProductInfoRelatedToInstallationPath[runtime.GOOS]
+	if err = addFileToBundle(w, productInfo, "product-info.json"); err != nil {
+		log.Printf("Could not add %s to the bundle: %s", productInfo, err)
+	}
+	for _, settingsFile := range ide.settingsFiles() {
+		if err = addFileToBundle(w, settingsFile, "settings/"+filepath.Base(settingsFile)); err != nil {
+			log.Printf("Could not add %s to the bundle: %s", settingsFile, err)
+		}
+	}
+	if err = addTextToBundle(w, "environment.txt", ide.environmentSummary()); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+//settingsFiles returns vmoptions of the installation and of the user, and idea.properties files that exist
+func (ide IDE) settingsFiles() (files []string) {
+	return existingFiles(append(ide.vmOptionsFiles(), ide.ideaPropertiesFiles()...))
+}
+
+func (ide IDE) environmentSummary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "IDE: %s %s\n", ide.Info.Name, ide.Info.Version)
+	fmt.Fprintf(&b, "Build: %s-%s\n", ide.Info.ProductCode, ide.Info.BuildNumber)
+	fmt.Fprintf(&b, "Running: %v\n", ide.Running)
+	fmt.Fprintf(&b, "Installation: %s\n", ide.Package)
+	fmt.Fprintf(&b, "Logs directory: %s\n", ide.Info.LogsDirectory)
+	fmt.Fprintf(&b, "System directory: %s\n", ide.SystemDirectory)
+	fmt.Fprintf(&b, "OS: %s %s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "Cores: %d\n", runtime.NumCPU())
+	fmt.Fprintf(&b, "Time zone: %s\n", time.Now().Format("MST -07:00"))
+	fmt.Fprintf(&b, "Collected: %s\n", time.Now().Format(time.RFC3339))
+	return b.String()
+}
+
+//addFolderToBundle adds files of the folder under the prefix keeping their relative paths. The bundle itself is skipped if it is saved inside the folder.
+func addFolderToBundle(w *zip.Writer, folder string, prefix string, zipPath string) error {
+	return filepath.WalkDir(folder, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			log.Printf("Skipping %s: %s", path, err)
+			return nil
+		}
+		if d.IsDir() || filepath.Clean(path) == filepath.Clean(zipPath) {
+			return nil
+		}
+		rel, err := filepath.Rel(folder, path)
+		if err != nil {
+			return nil
+		}
+		name := filepath.ToSlash(rel)
+		if len(prefix) > 0 {
+			name = prefix + "/" + name
+		}
+		if err := addFileToBundle(w, path, name); err != nil {
+			log.Printf("Could not add %s to the bundle: %s", path, err)
+		}
+		return nil
+	})
+}
+
+func addFileToBundle(w *zip.Writer, path string, name string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	info, err := source.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+	destination, err := w.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	if !textFileRegex.MatchString(path) {
+		_, err = io.Copy(destination, source)
+		return err
+	}
+	return copyRedacted(destination, source)
+}
+
+func addTextToBundle(w *zip.Writer, name string, text string) error {
+	destination, err := w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(destination, text)
+	return err
+}
+
+//copyRedacted copies text line by line replacing secrets with <redacted>
+func copyRedacted(destination io.Writer, source io.Reader) error {
+	reader := bufio.NewReader(source)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			if _, writeErr := io.WriteString(destination, RedactSecrets(line)); writeErr != nil {
+				return writeErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+//RedactSecrets replaces passwords, tokens, keys and credentials of URLs in the text
+func RedactSecrets(text string) string {
+	for _, rule := range secretRules {
+		text = rule.regex.ReplaceAllString(text, rule.replacement)
+	}
+	return text
+}
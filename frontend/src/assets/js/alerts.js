@@ -0,0 +1,69 @@
+const applicationTitle = "IntelliJ Log Analyzer"
+//unreadAlerts is shown in the window title and on the "Live alerts" tab until the tab is opened
+let unreadAlerts = 0
+
+document.addEventListener('DOMContentLoaded', function () {
+    window.runtime.EventsOn("AlertRaised", async function (rule, text) {
+        unreadAlerts++
+        showNativeNotification(rule, text)
+        await refreshAlertsToolWindow()
+        updateAlertsBadge()
+    })
+    $("#toolWindows-buttons").on('click', `.toolWindowButton[target='${getObjectID("Live alerts")}']`, function () {
+        resetUnreadAlerts()
+    })
+    toolWindows.on('click', '.live-alerts .find-alert', function () {
+        let editor = ace.edit(window.mainEditorID)
+        editor.find($(this).attr("target"), {
+            wrap: true,
+            caseSensitive: true,
+            regExp: false,
+        })
+    });
+})
+
+async function refreshAlertsToolWindow() {
+    let content = await window.go.main.App.GetAlerts()
+    let toolWindow = $("#" + getObjectID("Live alerts"))
+    if (toolWindow.length) {
+        toolWindow.html(content)
+    } else {
+        await showToolWindow("Live alerts", "livealerts", "bot", "", content)
+        setSidebarState()
+    }
+}
+
+function updateAlertsBadge() {
+    let tab = $(`#toolWindows-buttons .toolWindowButton[target='${getObjectID("Live alerts")}']`)
+    tab.find(".badge").remove()
+    if (unreadAlerts > 0) {
+        tab.append(`<span class="badge">${unreadAlerts}</span>`)
+        window.runtime.WindowSetTitle(`(${unreadAlerts}) ${applicationTitle}`)
+    } else {
+        window.runtime.WindowSetTitle(applicationTitle)
+    }
+}
+
+function resetUnreadAlerts() {
+    unreadAlerts = 0
+    updateAlertsBadge()
+}
+
+//showNativeNotification uses system notifications if the web view supports them, otherwise the alert is shown inside the app
+function showNativeNotification(title, text) {
+    if (!("Notification" in window) || Notification.permission === "denied") {
+        showNotification("warn", `${title}: ${$("<div>").text(text).html()}`)
+        return
+    }
+    if (Notification.permission === "granted") {
+        new Notification(title, {body: text})
+        return
+    }
+    Notification.requestPermission().then(function (permission) {
+        if (permission === "granted") {
+            new Notification(title, {body: text})
+        } else {
+            showNotification("warn", `${title}: ${$("<div>").text(text).html()}`)
+        }
+    })
+}
@@ -33,6 +33,11 @@ async function showEditor(name, content) {
                 editor.renderer.scrollToLine(Number.POSITIVE_INFINITY)
             }
         });
+        editor.session.on('changeScrollTop', function () {
+            if (id === window.mainEditorID) {
+                scheduleWorkspaceSave()
+            }
+        });
     }
     async function setEditorOptions(editor, optList) {
         editor.setFontSize(optList.fontSize)
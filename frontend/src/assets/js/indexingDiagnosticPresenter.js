@@ -3,11 +3,25 @@ let IndexingDiagnosticLinkHandler = async function (e) {
     let pos = editor.getCursorPosition()
     let token = editor.session.getTokenAt(pos.row, pos.column)
     if ((token.type !== null) && (/IndexingDiagnosticHyperlink/.test(token.type))) {
-        await window.go.main.App.OpenIndexingReport(token.value)
+        await openIndexingReport(token.value, await window.go.main.App.GetIndexingReportURL(token.value))
     } else if ((token.type !== null) && (/IndexingProjectDiagnosticHyperlink/.test(token.type))) {
         let lineLength = editor.session.getLine(pos.row).length
         token = editor.session.getTokenAt(pos.row, lineLength-1)
-        await window.go.main.App.OpenIndexingSummaryForProject(token.value)
+        await openIndexingReport("Indexing summary", await window.go.main.App.GetIndexingSummaryURL(token.value))
     }
 
-}
\ No newline at end of file
+}
+
+//openIndexingReport shows the report served by the backend in a sandboxed frame next to the log editor
+async function openIndexingReport(name, url) {
+    if (!url) {
+        showNotification("warn", "Indexing report is not found")
+        return
+    }
+    let editorName = getObjectID("indexing report " + url.replace(/\W/g, ""))
+    if (!editors.find(`.${editorName}`).length) {
+        editors.append(`<div class="${editorName}"><iframe class="indexing-report" sandbox="allow-scripts" src="${url}"></iframe></div>`)
+    }
+    await showToolWindow(name, "IndexingReport", "top", editorName, `<p>${url.split("/").pop()}</p>`)
+    await showEditor(editorName)
+}
@@ -25,13 +25,16 @@ define('ace/mode/idea_log_highlight_rules', [], function (require, exports, modu
                 regex: /^$/,
                 token: "empty_line"
             },{
-                regex: /ERROR|PARSE_ERROR|FREEZE|STDERR|EXCPT/,
+                regex: /ERROR|PARSE_ERROR|FREEZE|STDERR|EXCPT|GC_LONG_PAUSE|CRASH/,
                 token: "loglevel.error",
+            }, {
+                regex: /\[plugin: [^\]]*\]/,
+                token: "loglevel.warn"
             }, {
                 regex: /\s+—\s+(.*?)\s+—\s+/,
                 token: "variable.class"
             }, {
-                regex: /INFO|INDEX|SEVERE|VERB|TRACE/,
+                regex: /INFO|INDEX|SEVERE|VERB|TRACE|GC_PAUSE/,
                 token: "loglevel.info",
             },{
                 regex: /WARN|STDERR/,
@@ -40,6 +43,9 @@ define('ace/mode/idea_log_highlight_rules', [], function (require, exports, modu
                 regex: /(threadDump\S*(?=\s)*)/,
                 token: "ThreadDumpsHyperlink",
             },{
+                regex: /(Indexing report: )(diagnostic-\S+\.html)/,
+                token: ["text", "IndexingDiagnosticHyperlink"],
+            }, {
                 regex: /(Indexing project:.*)(report.html)(.*Report: )(.*\.html)/,
                 token: ["text", "IndexingProjectDiagnosticHyperlink", "text", "IndexingDiagnosticHyperlink"],
             }, {
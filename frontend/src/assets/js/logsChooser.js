@@ -6,6 +6,7 @@ const archiveSelector = $("#select-archive")
 const fileUploader = $("#file-uploader");
 const fileAnalyzer = $("#file-analyzer");
 const IdeSelector = $("#select-running-ide")
+const recentItems = $("#recent-items")
 
 $(document).ready(async function () {
     let zipWriter = new zip.ZipWriter(new zip.Data64URIWriter("application/zip"));
@@ -58,6 +59,7 @@ $(document).ready(async function () {
 
     //inserts the list of running and installed IDEs into #select-running-ide .dropdown
     IdeSelector.find(".options").first().html(await window.go.main.App.GetRunningIDEsDropdownHTML())
+    await renderRecentItems()
     window.addEventListener('dragenter', function (ev) {
         lastTarget = ev.target;
         dropzone.css('visibility', 'visible');
@@ -94,9 +96,7 @@ $(document).ready(async function () {
             }
         }
         if (result) {
-            fileUploader.hide();
-            fileAnalyzer.show();
-            render()
+            showAnalysis(result)
         }
         loader.hide();
         disclamer.show();
@@ -109,29 +109,102 @@ $(document).ready(async function () {
     });
     directorySelector.on('click', async () => {
         let path = await window.go.main.App.OpenFolder()
-        initLogDirectory(path)
+        showAnalysis(await window.go.main.App.OpenLogFolder(path))
     })
     archiveSelector.on('click', async () => {
-        let path = await window.go.main.App.OpenArchive()
-        initLogDirectory(path)
+        showAnalysis(await window.go.main.App.OpenArchive())
     })
     IdeSelector.find(".button").first().on('click', async function () {
         let path = IdeSelector.find("li.active").attr("target");
+        let includeSystemDiagnostics = IdeSelector.find(".include-system-diagnostics input").prop("checked")
         $(this).html("Loading...");
-        await initLogDirectory(path)
-        window.go.main.App.EnableLogsLiveUpdate()
+        let openedLogsDir = await window.go.main.App.OpenInstalledIDE(path, includeSystemDiagnostics)
+        if (!await showAnalysis(openedLogsDir)) {
+            $(this).html("Could not open logs. Retry");
+        }
+    })
+    IdeSelector.find(".inspect-ide").on('click', async function () {
+        let path = IdeSelector.find("li.active").attr("target");
+        let button = $(this)
+        button.html("Inspecting...");
+        let html = await window.go.main.App.GetIdeInspectorHTML(path)
+        button.html("Inspect");
+        if (html.length > 0) {
+            showIdeInspector(html)
+        } else {
+            button.html("Could not inspect. Retry");
+        }
+    })
+    IdeSelector.find(".collect-logs").on('click', async function () {
+        let path = IdeSelector.find("li.active").attr("target");
+        let button = $(this)
+        button.html("Collecting...");
+        let bundleDir = await window.go.main.App.CollectSupportBundle(path)
+        button.html("Collect Logs");
+        showAnalysis(bundleDir)
+    })
+    recentItems.on('click', '.recent-item', async function (e) {
+        let index = parseInt($(this).attr("target"))
+        let status = $(this).find(".recent-item-opened")
+        if ($(e.target).hasClass("recent-item-remove")) {
+            let error = await window.go.main.App.RemoveRecentItem(index)
+            if (error) {
+                status.text("Could not remove").attr("title", error)
+            } else {
+                await renderRecentItems()
+            }
+            return
+        }
+        status.text("Loading...")
+        let error = await window.go.main.App.OpenRecentItem(index)
+        if (error) {
+            status.text("Could not open. Retry").attr("title", error)
+        } else {
+            showAnalysis("opened")
+        }
     })
 })
 document.addEventListener('DOMContentLoaded', function () {
     window.runtime.EventsOn("LogsUpdated", function (s) {
         appendToMainEditor(s)
     })
+    window.runtime.EventsOn("FilesAdded", async function (files) {
+        showNotification("info", "New files: " + files.join(", "))
+        await refreshMainScreen()
+    })
 })
-async function initLogDirectory(path) {
-    let openedLogsDir = await window.go.main.App.InitLogDirectory(path)
-    if (openedLogsDir.length > 0) {
-        fileUploader.hide();
-        fileAnalyzer.show();
-        render()
+//showIdeInspector shows the overlay with the IDE inspection. It is closed by click outside, close button or Escape
+function showIdeInspector(html) {
+    $("#ide-inspector-overlay").remove();
+    $("body").append(html);
+    let overlay = $("#ide-inspector-overlay");
+    overlay.on('click', function (e) {
+        if (e.target === this || $(e.target).hasClass("ide-inspector-close")) {
+            overlay.remove();
+        }
+    });
+    $(document).on('keydown.ideInspector', function (e) {
+        if (e.key === "Escape") {
+            overlay.remove();
+            $(document).off('keydown.ideInspector');
+        }
+    });
+}
+
+//showAnalysis switches to the analysis screen if logs were opened and restores the workspace saved for them. Returns false if openedPath is empty
+async function showAnalysis(openedPath) {
+    if (!openedPath) {
+        return false
     }
+    workspaceRestored = false
+    fileUploader.hide();
+    fileAnalyzer.show();
+    await render()
+    await restoreWorkspace()
+    return true
+}
+
+//renderRecentItems inserts the list of recently analyzed folders, archives and IDEs into #recent-items
+async function renderRecentItems() {
+    recentItems.html(await window.go.main.App.GetRecentItemsHTML())
 }
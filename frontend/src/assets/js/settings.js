@@ -24,6 +24,11 @@ document.addEventListener('DOMContentLoaded', function () {
         applySettingsForAllEditors(settings);
         setPreferableColorScheme();
     });
+    window.runtime.EventsOn("LogsTimeZoneChanged", async function () {
+        if (fileAnalyzer.is(":visible")) {
+            await redrawEditors();
+        }
+    });
 
     $(document).keydown(function (e) {
         if ((e.key === "Escape") && settingsOverlay.is(":visible")) {
@@ -39,8 +44,26 @@ async function ShowSettings() {
     });
 }
 
-function SaveSetting(id, option) {
-    window.go.main.App.SaveSetting(id, option);
+//SaveSetting saves the setting and shows the error in the settings screen if the value is not accepted. Returns error message or empty string
+async function SaveSetting(id, option) {
+    let error = await window.go.main.App.SaveSetting(id, option);
+    $("#settings-overlay .settings-error").text(error);
+    return error
+}
+
+//SaveHiddenEntities saves names of log types unchecked in "Visible By Default" list
+function SaveHiddenEntities() {
+    let hidden = $("#settings-overlay .entity-visibility input:not(:checked)").map(function () {
+        return this.value
+    }).get()
+    SaveSetting("HiddenEntities", hidden.join("\n"))
+}
+
+//SaveIdeSearchRoots saves folders to search IDEs in and rescans installations shown on the start screen
+async function SaveIdeSearchRoots(id, option) {
+    if (await SaveSetting(id, option) === "") {
+        IdeSelector.find(".options").first().html(await window.go.main.App.GetRunningIDEsDropdownHTML())
+    }
 }
 
 $(document).on("DOMSubtreeModified", ".settings-section-content-item .dropdown", function () {
@@ -49,5 +72,5 @@ $(document).on("DOMSubtreeModified", ".settings-section-content-item .dropdown",
     if (parseInt(settingValue)) {
         settingValue = parseInt(settingValue)
     }
-    window.go.main.App.SaveSetting(settingId, settingValue);
+    SaveSetting(settingId, settingValue);
 })
\ No newline at end of file
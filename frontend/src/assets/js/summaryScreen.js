@@ -1,6 +1,7 @@
 //Clear Tool Windows, and redraw editors
 const render = async () => {
     await clearToolWindows();
+    resetUnreadAlerts()
     await redrawEditors()
     $(".group-label>.folding-icon").click();
 }
@@ -11,14 +12,46 @@ const redrawEditors = async () => {
     await renderMainScreen();
 }
 
+//Re-create main tool windows and editors after new files were found by live update. Tool windows opened by user are kept.
+const refreshMainScreen = async () => {
+    await saveWorkspaceState()
+    for (const name of mainToolWindows) {
+        let id = getObjectID(name)
+        $(`#toolWindows-buttons .toolWindowButton[target='${id}']`).remove()
+        $("#" + id).remove()
+    }
+    await redrawEditors()
+    $("#summary .group-label>.folding-icon").click();
+}
+
 //Get Summary Screen from server
 async function renderMainScreen() {
     await showToolWindow("Summary", "filters", "top", "Main Editor", window.go.main.App.GetSummary())
     setSummaryToolWindowGroupCheckboxStates()
     addSummaryToolWindowListeners()
+    let notes = await getNotesHTML()
+    if (notes) {
+        await showToolWindow("Notes", "notes", "bot", "", notes)
+    }
     if (await window.go.main.App.GetStaticInfo()) {
         await showToolWindow("Static Info", "staticinfo", "bot", "", window.go.main.App.GetStaticInfo())
     }
+    if (await window.go.main.App.GetGCChart()) {
+        await showToolWindow("GC", "gcchart", "bot", "", window.go.main.App.GetGCChart())
+    }
+    if (await window.go.main.App.GetIndexingHistory()) {
+        await showToolWindow("Indexing", "indexinghistory", "bot", "", window.go.main.App.GetIndexingHistory())
+    }
+    if (await window.go.main.App.GetCrashReports()) {
+        await showToolWindow("Crashes", "crashreports", "bot", "", window.go.main.App.GetCrashReports())
+    }
+    if (await window.go.main.App.GetPluginErrors()) {
+        await showToolWindow("Plugin errors", "pluginerrors", "bot", "", window.go.main.App.GetPluginErrors())
+    }
+    if (await window.go.main.App.GetAlerts()) {
+        await showToolWindow("Live alerts", "livealerts", "bot", "", window.go.main.App.GetAlerts())
+        updateAlertsBadge()
+    }
     setSidebarState();
     function addSummaryToolWindowListeners() {
         $("#summary .link.show-in-editor").on("click",async function (e){
@@ -5,6 +5,8 @@ const clearToolWindows = async () => {
     tabs.remove();
 }
 const toolWindows = $("#toolWindows")
+//mainToolWindows are rendered by renderMainScreen and cannot be closed
+const mainToolWindows = ["Summary", "Notes", "Static Info", "GC", "Indexing", "Crashes", "Plugin errors", "Live alerts"]
 
 $(document).ready(function () {
     // Event handler for filter checkboxes
@@ -15,6 +17,7 @@ $(document).ready(function () {
             filters[$(this).val()] = $(this).prop('checked');
         })
         await window.go.main.App.SetFilters(filters).then(redrawEditors())
+        scheduleWorkspaceSave()
         //Group check/uncheck functionality
         async function checkChildElements(elem) {
             var checked = $(elem).prop('checked');
@@ -69,6 +72,36 @@ $(document).ready(function () {
 
     })
 
+    toolWindows.on('click', '.export-static-info', function () {
+        window.go.main.App.ExportStaticInfo()
+    });
+    toolWindows.on('click', '.plugin-link', function (e) {
+        e.preventDefault()
+        window.runtime.BrowserOpenURL($(this).attr("data-link"))
+    });
+    toolWindows.on('click', '.plugin-errors .find-plugin-errors', function () {
+        let editor = ace.edit(window.mainEditorID)
+        editor.find($(this).attr("target"), {
+            wrap: true,
+            caseSensitive: true,
+            regExp: false,
+        })
+    });
+    toolWindows.on('click', '.indexing-findings .open-indexing-report', async function () {
+        let name = $(this).attr("target")
+        await openIndexingReport(name, await window.go.main.App.GetIndexingReportURL(name))
+    });
+    //show full text of JVM fatal error log
+    toolWindows.on('click', '.crash-report .show-crash-report', function () {
+        let reportID = $(this).attr("target");
+        let editorName = getObjectID(reportID)
+        showEditor(editorName, window.go.main.App.GetCrashReportContent(reportID)).then(function () {
+            let editor = ace.edit(editorName)
+            editor.renderer.scrollToLine(0)
+            editor.clearSelection();
+        })
+    });
+
     //show/hide other files on click
     toolWindows.on('click', '.other-files li', function () {
         let fileUUID = $(this).attr("target");
@@ -153,7 +186,7 @@ async function showToolWindow(name, cssClass, position, linkedEditor, fillFuncti
 
     function createToolWindowTabElement() {
         let closeButton = function () {
-            if (id !== getObjectID("Summary") && id !== getObjectID("Static Info")) {
+            if (!mainToolWindows.some(name => getObjectID(name) === id)) {
                 return '<span class="closebtn">&times;</span>'
             }
             return ''
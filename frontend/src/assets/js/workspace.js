@@ -0,0 +1,60 @@
+//Search, scroll position of the main editor, notes and filters of logs from the recent list are saved on change and restored when the logs are opened again
+let workspaceSaveTimer;
+let workspaceRestored = false;
+let workspaceNotes = ""; // workspaceNotes keeps notes while Notes tool window is re-created
+
+$(document).ready(function () {
+    $(document).on('input', '#editors .search-box .ace_search_field, #toolWindows .notes textarea', scheduleWorkspaceSave)
+})
+
+//restoreWorkspace applies search, scroll position and notes saved for analyzed logs. Filters are restored by the backend when logs are opened
+async function restoreWorkspace() {
+    let state = await window.go.main.App.GetWorkspaceState()
+    if (state) {
+        state = JSON.parse(state)
+        let editor = ace.edit(window.mainEditorID)
+        if (state.Search && editor.searchBox) {
+            editor.searchBox.searchInput.value = state.Search
+            editor.searchBox.find(false, false, true)
+        }
+        if (state.ScrollLine > 0) {
+            editor.scrollToLine(state.ScrollLine, false, false)
+        }
+    }
+    workspaceRestored = true
+}
+
+//getNotesHTML returns the content of Notes tool window, or empty string if analyzed logs are not in the recent list and notes could not be saved
+async function getNotesHTML() {
+    let state = await window.go.main.App.GetWorkspaceState()
+    if (!state) {
+        return ""
+    }
+    workspaceNotes = JSON.parse(state).Notes
+    return $("<textarea spellcheck='false' placeholder='Notes are saved with this analysis and shown in the recent list'></textarea>")
+        .text(workspaceNotes)
+        .prop("outerHTML")
+}
+
+//scheduleWorkspaceSave saves the workspace state when user stops changing it
+function scheduleWorkspaceSave() {
+    if (!workspaceRestored) {
+        return
+    }
+    clearTimeout(workspaceSaveTimer)
+    workspaceSaveTimer = setTimeout(saveWorkspaceState, 500)
+}
+
+async function saveWorkspaceState() {
+    clearTimeout(workspaceSaveTimer)
+    if (!workspaceRestored || !window.mainEditorID || !$(`#${window.mainEditorID}`).length) {
+        return
+    }
+    let editor = ace.edit(window.mainEditorID)
+    let search = editor.searchBox ? editor.searchBox.searchInput.value : ""
+    let notes = $("#toolWindows .notes textarea")
+    if (notes.length) {
+        workspaceNotes = notes.val()
+    }
+    await window.go.main.App.SaveWorkspaceState(search, editor.getFirstVisibleRow(), workspaceNotes)
+}
@@ -4,6 +4,7 @@ import (
 	"embed"
 	"github.com/wailsapp/wails/v2/pkg/options/mac"
 	"log"
+	"log_analyzer/backend"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/logger"
@@ -38,6 +39,7 @@ func main() {
 		StartHidden:       false,
 		HideWindowOnClose: false,
 		Assets:            assets,
+		AssetsHandler:     backend.NewIndexingReportHandler(),
 		LogLevel:          logger.DEBUG,
 		Logger:            NewAppLogger(),
 		OnStartup:         app.startup,